@@ -36,6 +36,7 @@ func init() {
 		versionCmd,
 		initRedis,
 		initRedisAll,
+		verifyChainCmd,
 	)
 	models.InitBuildInfo()
 	// This flags are visible for all child commands
@@ -0,0 +1,46 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/IBAX-io/go-explorer/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyFrom int64
+	verifyTo   int64
+)
+
+// verifyChainCmd re-checks the block hash chain and block signatures
+var verifyChainCmd = &cobra.Command{
+	Use:    "verify-chain",
+	Short:  "Verify block hash chain and signatures",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := conf.GetEnvConf().DatabaseInfo.GormInit(); err != nil {
+			log.WithError(err).Fatal("postgres database connect failed")
+		}
+		defer models.GormClose()
+		if err := models.InitBlockIntegrityReport(); err != nil {
+			log.WithError(err).Fatal("init block integrity report")
+		}
+		rlt, err := models.VerifyChain(verifyFrom, verifyTo)
+		if err != nil {
+			log.WithError(err).Fatal("verify chain")
+		}
+		fmt.Printf("verify chain from %d to %d: checked %d, errors %d, warnings %d\n", rlt.From, rlt.To, rlt.Checked, rlt.Errors, rlt.Warnings)
+	},
+}
+
+func init() {
+	verifyChainCmd.Flags().Int64Var(&verifyFrom, "from", 1, "first block id to verify")
+	verifyChainCmd.Flags().Int64Var(&verifyTo, "to", 0, "last block id to verify, 0 means the max block")
+}
@@ -34,6 +34,8 @@ type EnvConf struct {
 	Crontab        *storage.Crontab          `yaml:"crontab"`
 	CryptoSettings storage.CryptoSettings    `yaml:"crypto_settings"`
 	Defi           defiInfo                  `yaml:"defi"`
+	Price          priceInfo                 `yaml:"price"`
+	TokenList      tokenListInfo             `yaml:"token_list"`
 }
 
 type defiInfo struct {
@@ -41,6 +43,29 @@ type defiInfo struct {
 	Ecosystem int64 `yaml:"ecosystem"`
 }
 
+type priceInfo struct {
+	Sources   []priceSourceInfo `yaml:"sources"`    // price sources in fallback order, the dex source is used if empty
+	FiatRates map[string]string `yaml:"fiat_rates"` // units of each currency per USD
+}
+
+type tokenListInfo struct {
+	Name     string   `yaml:"name"`     // token list name
+	ChainId  int64    `yaml:"chain_id"` // the network id of the ibax chain, 0 reads it from the chain
+	LogoURI  string   `yaml:"logo_uri"`
+	Keywords []string `yaml:"keywords"`
+}
+
+type priceSourceInfo struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`      // dex,http,static
+	Currency string `yaml:"currency"`  // quote currency of the source prices, default USD
+	MaxAge   int64  `yaml:"max_age"`   // seconds, the price is flagged stale when older, 0 never stale
+	Url      string `yaml:"url"`       // http: request url, {ecosystem} and {symbol} are replaced
+	JsonPath string `yaml:"json_path"` // http: the price path of the response, e.g. data.0.price
+	Refresh  int64  `yaml:"refresh"`   // http: seconds to cache the response, default 60
+	File     string `yaml:"file"`      // static: fixture file path
+}
+
 func GetEnvConf() *EnvConf {
 	return &configInfo
 }
@@ -22,6 +22,7 @@ type serverModel struct {
 	TokenExpireSecond    time.Duration `yaml:"token_expire_second"`     // token expire second
 	SystemStaticFilePath string        `yaml:"system_static_file_path"` // system static file path
 	DocsApi              string        `yaml:"docs_api"`                // api docs request address
+	AdminToken           string        `yaml:"admin_token"`             // admin api bearer token, admin api is disabled if empty
 }
 
 type UrlModel struct {
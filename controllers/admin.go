@@ -0,0 +1,30 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth checks the bearer token of the admin api against server.admin_token
+func AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ret := &Response{}
+		token := conf.GetEnvConf().ServerInfo.AdminToken
+		auth := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer"))
+		if token == "" || auth == "" || subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 {
+			ret.Return(nil, CodePermissionDenied)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ret)
+			return
+		}
+		c.Next()
+	}
+}
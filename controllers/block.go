@@ -196,3 +196,46 @@ func GetTxListChart(c *gin.Context) {
 	JsonResponse(c, ret)
 	return
 }
+
+type blockIntegrityRequest struct {
+	GeneralRequest
+	Level   int   `json:"level"`
+	BlockId int64 `json:"block_id"`
+}
+
+func GetBlockIntegrityReportHandler(c *gin.Context) {
+	ret := &Response{}
+	req := &blockIntegrityRequest{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 || req.Level < 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	rets, err := models.GetBlockIntegrityReport(req.Page, req.Limit, req.Level, req.BlockId)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetBlockIntegritySummaryHandler(c *gin.Context) {
+	ret := &Response{}
+	rets, err := models.GetBlockIntegritySummary()
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
@@ -0,0 +1,84 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func GetBridgeTransfersHandler(c *gin.Context) {
+	req := &models.BridgeTransferFind{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 || req.SettingId < 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetBridgeTransfers(req)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetBridgePendingWithdrawalsHandler(c *gin.Context) {
+	req := &models.BridgeTransferFind{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 || req.SettingId < 0 || req.Days < 0 || req.Days > models.BridgePendingMaxDays {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetBridgePendingWithdrawals(req.SettingId, req.Days, req.Page, req.Limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetBridgeVolumeChartHandler(c *gin.Context) {
+	ret := &Response{}
+	settingId := converter.StrToInt64(c.Param("id"))
+	if settingId <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	days := int(converter.StrToInt64(c.DefaultQuery("days", "30")))
+
+	rets, err := models.GetBridgeVolumeChart(settingId, days)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
@@ -0,0 +1,83 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func GetBurnLedgerHandler(c *gin.Context) {
+	req := &EcosytemTranscationHistoryFind{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 || req.Ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetBurnLedger(req.Ecosystem, req.Page, req.Limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetBurnSummaryHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem := converter.StrToInt64(c.Param("ecosystem"))
+	if ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	limit := int(converter.StrToInt64(c.DefaultQuery("limit", "10")))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	rets, err := models.GetBurnSummary(ecosystem, limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetBurnChartHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem := converter.StrToInt64(c.Param("ecosystem"))
+	if ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	days := int(converter.StrToInt64(c.DefaultQuery("days", "30")))
+
+	rets, err := models.GetBurnChart(ecosystem, days)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
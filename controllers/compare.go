@@ -0,0 +1,52 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type ecosystemCompareRequest struct {
+	Ecosystems []int64 `json:"ecosystems"`
+	Days       int     `json:"days"` //the days of the series, default 30
+}
+
+func GetEcosystemCompareHandler(c *gin.Context) {
+	req := &ecosystemCompareRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Days == 0 {
+		req.Days = models.CompareDefaultDays
+	}
+	if len(req.Ecosystems) < models.CompareMinEcosystems || len(req.Ecosystems) > models.CompareMaxEcosystems {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	for _, v := range req.Ecosystems {
+		if v <= 0 {
+			ret.ReturnFailureString("request params invalid")
+			JsonResponse(c, ret)
+			return
+		}
+	}
+
+	rets, err := models.GetEcosystemCompare(req.Ecosystems, req.Days)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
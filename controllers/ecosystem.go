@@ -212,6 +212,8 @@ func GetEcosystemDetailTxHandler(c *gin.Context) {
 			txList[i].Contract = models.GetUtxoTxContractNameByHash(ts[i].Hash)
 		}
 		txList[i].Address = converter.AddressToString(ts[i].Address)
+		txList[i].Iname = models.GetIName(txList[i].Address)
+		txList[i].Label = models.AddressLabels.Label(txList[i].Address)
 		txList[i].Hash = hex.EncodeToString(ts[i].Hash)
 		txList[i].Status = ts[i].Status
 	}
@@ -0,0 +1,111 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type feeAnalyticsRequest struct {
+	Ecosystem int64 `json:"ecosystem"`
+	StartTime int64 `json:"startTime"`
+	EndTime   int64 `json:"endTime"`
+	Page      int   `json:"page"`
+	Limit     int   `json:"limit"`
+}
+
+func GetFeeRecipientsHandler(c *gin.Context) {
+	req := &feeAnalyticsRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 || req.Ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetFeeRecipients(req.Ecosystem, req.StartTime, req.EndTime, req.Page, req.Limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetNodeFeeIncomeHandler(c *gin.Context) {
+	req := &feeAnalyticsRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Ecosystem <= 0 {
+		req.Ecosystem = 1
+	}
+
+	rets, err := models.GetNodeFeeIncome(req.Ecosystem, req.StartTime, req.EndTime)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetFeeComponentChartHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem := converter.StrToInt64(c.Param("ecosystem"))
+	if ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	days := int(converter.StrToInt64(c.DefaultQuery("days", "30")))
+
+	rets, err := models.GetFeeComponentChart(ecosystem, days)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetFeeEstimateHandler(c *gin.Context) {
+	ret := &Response{}
+	contract := c.Query("contract")
+	ecosystem := converter.StrToInt64(c.DefaultQuery("ecosystem", "1"))
+	if contract == "" || ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetFeeEstimate(contract, ecosystem)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
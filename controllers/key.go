@@ -6,6 +6,8 @@
 package controllers
 
 import (
+	"errors"
+
 	"github.com/IBAX-io/go-explorer/models"
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/gin-gonic/gin"
@@ -169,3 +171,141 @@ func GetAccountTxChart(c *gin.Context) {
 	JsonResponse(c, ret)
 
 }
+
+func ConvertAddressHandler(c *gin.Context) {
+	ret := &Response{}
+	value := c.Param("value")
+
+	rets, err := models.ConvertAddress(value)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func ValidateAddressHandler(c *gin.Context) {
+	ret := &Response{}
+	address := c.Param("address")
+
+	rets := models.ValidateAddress(address)
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+type addressFromPubKeyRequest struct {
+	PublicKey string `json:"public_key"`
+}
+
+func (r *addressFromPubKeyRequest) Validate() error {
+	if r.PublicKey == "" {
+		return errors.New("public key is empty")
+	}
+	return nil
+}
+
+func AddressFromPubKeyHandler(c *gin.Context) {
+	req := &addressFromPubKeyRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.PublicKeyToAddress(req.PublicKey)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetKeyEcosystemsHandler(c *gin.Context) {
+	ret := &Response{}
+	account := c.Param("account")
+
+	rets, err := models.GetKeyEcosystems(account)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func ResolveINameHandler(c *gin.Context) {
+	ret := &Response{}
+	value := c.Param("value")
+	if value == "" {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.ResolveIName(value)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+type verifySignatureRequest struct {
+	Account   string `json:"account"`
+	PublicKey string `json:"public_key"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+func (r *verifySignatureRequest) Validate() error {
+	if r.Account == "" && r.PublicKey == "" {
+		return errors.New("account or public key is required")
+	}
+	if r.Signature == "" {
+		return errors.New("signature is empty")
+	}
+	return nil
+}
+
+func VerifySignatureHandler(c *gin.Context) {
+	req := &verifySignatureRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.VerifySignature(req.Account, req.PublicKey, req.Message, req.Signature)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
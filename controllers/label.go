@@ -0,0 +1,136 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"errors"
+
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type addressLabelListRequest struct {
+	GeneralRequest
+	Category string `json:"category"`
+	Keyword  string `json:"keyword"`
+}
+
+type saveAddressLabelRequest struct {
+	List []models.AddressLabel `json:"list"`
+}
+
+func (r *saveAddressLabelRequest) Validate() error {
+	if len(r.List) == 0 {
+		return errors.New("label list is empty")
+	}
+	for i := range r.List {
+		if r.List[i].Source == "" {
+			r.List[i].Source = models.LabelSourceAdmin
+		}
+	}
+	return nil
+}
+
+func GetAddressLabelListHandler(c *gin.Context) {
+	req := &addressLabelListRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetAddressLabelList(req.Page, req.Limit, req.Category, req.Keyword)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetAddressLabelHandler(c *gin.Context) {
+	ret := &Response{}
+	info := models.ValidateAddress(c.Param("account"))
+	if !info.Valid {
+		ret.ReturnFailureString(info.Message)
+		JsonResponse(c, ret)
+		return
+	}
+
+	label := &models.AddressLabel{}
+	f, err := label.Get(info.Address)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if !f {
+		ret.Return(nil, CodeRecordNotExists)
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(label, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func SaveAddressLabelHandler(c *gin.Context) {
+	req := &saveAddressLabelRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	if err := models.SaveAddressLabels(req.List); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(len(req.List), CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func DeleteAddressLabelHandler(c *gin.Context) {
+	ret := &Response{}
+	if err := models.DeleteAddressLabel(c.Param("account")); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(nil, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func ImportAddressLabelHandler(c *gin.Context) {
+	ret := &Response{}
+	count, err := models.ImportAddressLabelFile()
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(count, CodeSuccess)
+	JsonResponse(c, ret)
+}
@@ -0,0 +1,86 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type parameterTimelineRequest struct {
+	Ecosystem int64  `json:"ecosystem"` //0: platform parameters
+	Name      string `json:"name"`
+	Page      int    `json:"page"`
+	Limit     int    `json:"limit"`
+}
+
+func GetParameterTimelineHandler(c *gin.Context) {
+	req := &parameterTimelineRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 || req.Ecosystem < 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetParameterTimeline(req.Ecosystem, req.Name, req.Page, req.Limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetParameterHistoryHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem := converter.StrToInt64(c.Param("ecosystem"))
+	name := c.Param("name")
+	if ecosystem < 0 || name == "" {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetParameterHistory(ecosystem, name)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetFuelRateChartHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem := converter.StrToInt64(c.Param("ecosystem"))
+	if ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetFuelRateChart(ecosystem)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
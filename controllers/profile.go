@@ -0,0 +1,135 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type submitEcosystemProfileRequest struct {
+	Data      string `json:"data"`      //the json of the profile data, signed as is
+	Signature string `json:"signature"` //hex signature of data by the founder key
+}
+
+type ecosystemProfileListRequest struct {
+	Ecosystem int64 `json:"ecosystem"`
+	Status    *int  `json:"status"` //all if empty
+	Page      int   `json:"page"`
+	Limit     int   `json:"limit"`
+}
+
+type reviewEcosystemProfileRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note"`
+}
+
+func SubmitEcosystemProfileHandler(c *gin.Context) {
+	req := &submitEcosystemProfileRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Data == "" || req.Signature == "" {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.SubmitEcosystemProfile(req.Data, req.Signature)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetEcosystemProfileHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem := converter.StrToInt64(c.Param("ecosystem"))
+	if ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetEcosystemProfile(ecosystem)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if rets == nil {
+		ret.Return(nil, CodeRecordNotExists)
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetEcosystemProfileListHandler(c *gin.Context) {
+	req := &ecosystemProfileListRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	status := -1
+	if req.Status != nil {
+		status = *req.Status
+	}
+
+	rets, err := models.GetEcosystemProfileList(req.Ecosystem, status, req.Page, req.Limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func ReviewEcosystemProfileHandler(c *gin.Context) {
+	req := &reviewEcosystemProfileRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	id := converter.StrToInt64(c.Param("id"))
+	if id <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.ReviewEcosystemProfile(id, req.Approve, req.Note)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
@@ -0,0 +1,144 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type holderSnapshotRequest struct {
+	Ecosystem int64 `json:"ecosystem"`
+	Block     int64 `json:"block"`
+}
+
+type holderSnapshotDiffRequest struct {
+	From  int64 `json:"from"`
+	To    int64 `json:"to"`
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+}
+
+func CreateHolderSnapshotHandler(c *gin.Context) {
+	req := &holderSnapshotRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Ecosystem <= 0 || req.Block < 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.CreateHolderSnapshot(req.Ecosystem, req.Block)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetHolderSnapshotHandler(c *gin.Context) {
+	ret := &Response{}
+	id := converter.StrToInt64(c.Param("id"))
+	if id <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetHolderSnapshot(id)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func DownloadHolderSnapshotHandler(c *gin.Context) {
+	ret := &Response{}
+	id := converter.StrToInt64(c.Param("id"))
+	format := c.DefaultQuery("format", "csv")
+	if id <= 0 || (format != "csv" && format != "json") {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetHolderSnapshotExport(id)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	var (
+		data        []byte
+		contentType string
+	)
+	if format == "json" {
+		data, err = json.Marshal(rets)
+		if err != nil {
+			ret.ReturnFailureString("Holder snapshot export json failed:" + err.Error())
+			JsonResponse(c, ret)
+			return
+		}
+		contentType = "application/json"
+	} else {
+		data = models.HolderSnapshotCSV(rets.List)
+		contentType = "text/csv"
+	}
+	fileName := fmt.Sprintf("snapshot_%d_%d.%s", rets.Ecosystem, rets.Block, format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Header("X-Content-Hash", rets.ContentHash)
+	c.Header("Access-Control-Allow-Origin", "*")
+	_, err = c.Writer.Write(data)
+	if err != nil {
+		ret.ReturnFailureString("Holder snapshot write error:" + err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+}
+
+func GetHolderSnapshotDiffHandler(c *gin.Context) {
+	req := &holderSnapshotDiffRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.From <= 0 || req.To <= 0 || req.Page <= 0 || req.Limit <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetHolderSnapshotDiff(req.From, req.To, req.Page, req.Limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
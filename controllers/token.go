@@ -5,13 +5,14 @@ import (
 	"github.com/IBAX-io/go-explorer/conf"
 	"github.com/IBAX-io/go-explorer/models"
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"strconv"
 	"strings"
 )
 
 type tokenPriceInfo struct {
-	Ecosystem  int64  `json:"ecosystem"`
-	PriceInUsd string `json:"price_in_usd"`
+	models.OraclePrice
+	PriceInUsd string `json:"price_in_usd,omitempty"`
 }
 
 func GetTokenPriceHandler(c *gin.Context) {
@@ -44,16 +45,28 @@ func GetTokenPriceHandler(c *gin.Context) {
 		}
 	}
 
-	prices, err := models.GetTokenPrices(ecosystems)
+	var (
+		prices   []models.OraclePrice
+		err      error
+		currency = strings.ToUpper(c.DefaultQuery("currency", models.DefaultPriceCurrency))
+	)
+	if t := c.Query("time"); t != "" {
+		//the historical price at the time
+		prices, err = getTokenPricesAt(ecosystems, t, currency)
+	} else {
+		prices, err = models.GetOraclePrices(ecosystems, currency)
+	}
 	if err != nil {
 		ret.ReturnFailureString(fmt.Sprintf("get prices %s", err))
 		JsonResponse(c, ret)
 		return
 	}
 	list := make([]tokenPriceInfo, len(prices))
-	for i, ecosystem := range ecosystems {
-		list[i].Ecosystem = ecosystem
-		list[i].PriceInUsd = prices[ecosystem]
+	for i, price := range prices {
+		list[i].OraclePrice = price
+		if price.Currency == models.DefaultPriceCurrency {
+			list[i].PriceInUsd = price.Price
+		}
 	}
 	ret.Return(list, CodeSuccess)
 	JsonResponse(c, ret)
@@ -111,3 +124,77 @@ func GetTokenLogoHandler(c *gin.Context) {
 	JsonResponse(c, ret)
 	return
 }
+
+func getTokenPricesAt(ecosystems []int64, timeStr, currency string) ([]models.OraclePrice, error) {
+	t, err := strconv.ParseInt(timeStr, 10, 64)
+	if err != nil || t <= 0 {
+		return nil, fmt.Errorf("time %s invalid", timeStr)
+	}
+	return models.GetOraclePricesAt(ecosystems, t, currency)
+}
+
+func GetTokenPriceCandlesHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem, _ := strconv.ParseInt(c.Query("ecosystem"), 10, 64)
+	resolution := c.DefaultQuery("resolution", "1h")
+	start, _ := strconv.ParseInt(c.Query("start"), 10, 64)
+	end, _ := strconv.ParseInt(c.Query("end"), 10, 64)
+	if ecosystem <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	if !conf.GetEnvConf().Defi.Enable {
+		ret.ReturnFailureString("defi not enable")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetPriceCandles(ecosystem, resolution, start, end)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetTokenPriceAtHandler(c *gin.Context) {
+	ret := &Response{}
+	ecosystem, _ := strconv.ParseInt(c.Query("ecosystem"), 10, 64)
+	t, _ := strconv.ParseInt(c.Query("time"), 10, 64)
+	if ecosystem <= 0 || t <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	if !conf.GetEnvConf().Defi.Enable {
+		ret.ReturnFailureString("defi not enable")
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.GetTokenPriceAt(ecosystem, t)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+// GetTokenListHandler the token list in the Uniswap token list schema, it is not wrapped by Response for the wallets
+func GetTokenListHandler(c *gin.Context) {
+	ret := &Response{}
+	rets, err := models.GetTokenList()
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	c.JSON(http.StatusOK, rets)
+}
@@ -226,6 +226,26 @@ func SearchHash(c *gin.Context) {
 
 }
 
+func GlobalSearchHandler(c *gin.Context) {
+	ret := &Response{}
+	q := c.Query("q")
+	if q == "" || utf8.RuneCountInString(q) > 100 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	rets, err := models.GlobalSearch(q, limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
 func GetTransactionHead(c *gin.Context) {
 
 	ret := &Response{}
@@ -734,3 +754,137 @@ func GetHistoryHandler(c *gin.Context) {
 	ret.Return(rlt, CodeSuccess)
 	JsonResponse(c, ret)
 }
+
+type decodeTxRequest struct {
+	Data string `json:"data" example:"hex or base64 serialized transaction"`
+}
+
+func (p *decodeTxRequest) Validate() error {
+	if p.Data == "" {
+		return errors.New("request params invalid! data can not be empty")
+	}
+	return nil
+}
+
+func DecodeTxHandler(c *gin.Context) {
+	req := &decodeTxRequest{}
+	ret := &Response{}
+	err := c.ShouldBindWith(req, binding.JSON)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	err = req.Validate()
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.DecodeRawTransaction(req.Data)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func BroadcastTxHandler(c *gin.Context) {
+	req := &decodeTxRequest{}
+	ret := &Response{}
+	err := c.ShouldBindWith(req, binding.JSON)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	err = req.Validate()
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	rets, err := models.BroadcastTransaction(req.Data)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetBroadcastTxStatusHandler(c *gin.Context) {
+	ret := &Response{}
+	hash := c.Param("hash")
+	if hash == "" || utf8.RuneCountInString(hash) > 100 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	rets, err := models.GetBroadcastTxStatus(hash)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetTxProofHandler(c *gin.Context) {
+	ret := &Response{}
+	hash := c.Param("hash")
+	if hash == "" || utf8.RuneCountInString(hash) > 100 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	rets, err := models.GetTxProof(hash)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+type verifyTxProofRequest struct {
+	TxHash string                   `json:"tx_hash"`
+	Root   string                   `json:"root"`
+	Proof  []models.MerkleProofStep `json:"proof"`
+}
+
+func VerifyTxProofHandler(c *gin.Context) {
+	req := &verifyTxProofRequest{}
+	ret := &Response{}
+	err := c.ShouldBindWith(req, binding.JSON)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.TxHash == "" || req.Root == "" {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	valid, err := models.VerifyMerkleProof(req.TxHash, req.Proof, req.Root)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(gin.H{"valid": valid}, CodeSuccess)
+	JsonResponse(c, ret)
+}
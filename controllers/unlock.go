@@ -0,0 +1,79 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package controllers
+
+import (
+	"github.com/IBAX-io/go-explorer/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type unlockScheduleRequest struct {
+	Account  string `json:"account"`
+	Interval string `json:"interval"` //day or month
+	Months   int    `json:"months"`
+}
+
+type upcomingUnlocksRequest struct {
+	Account string `json:"account"`
+	Days    int64  `json:"days"`
+	Page    int    `json:"page"`
+	Limit   int    `json:"limit"`
+}
+
+func GetUnlockScheduleChartHandler(c *gin.Context) {
+	req := &unlockScheduleRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Interval == "" {
+		req.Interval = models.UnlockIntervalMonth
+	}
+	if req.Months == 0 {
+		req.Months = 12
+	}
+
+	rets, err := models.GetUnlockScheduleChart(req.Account, req.Interval, req.Months)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
+
+func GetUpcomingUnlocksHandler(c *gin.Context) {
+	req := &upcomingUnlocksRequest{}
+	ret := &Response{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Page <= 0 || req.Limit <= 0 {
+		ret.ReturnFailureString("request params invalid")
+		JsonResponse(c, ret)
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+
+	rets, err := models.GetUpcomingUnlocks(req.Account, req.Days, req.Page, req.Limit)
+	if err != nil {
+		ret.ReturnFailureString(err.Error())
+		JsonResponse(c, ret)
+		return
+	}
+
+	ret.Return(rets, CodeSuccess)
+	JsonResponse(c, ret)
+}
@@ -57,6 +57,7 @@ func StartDaemons(ctx context.Context) {
 	}()
 
 	go SyncCentrifugoWork(ctx)
+	go PendingTxStatusWork(ctx)
 
 	go func() {
 		err := InitReport()
@@ -85,6 +86,38 @@ func StartDaemons(ctx context.Context) {
 		if err != nil {
 			ExitCh <- fmt.Errorf("init logo hash %s", err.Error())
 		}
+		err = models.InitBlockIntegrityReport()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init block integrity report %s", err.Error())
+		}
+		err = models.InitAddressLabel()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init address label %s", err.Error())
+		}
+		err = models.InitHolderDistribution()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init holder distribution %s", err.Error())
+		}
+		err = models.InitHolderSnapshot()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init holder snapshot %s", err.Error())
+		}
+		err = models.InitParameterChange()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init parameter change %s", err.Error())
+		}
+		err = models.InitTokenPriceHistory()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init token price history %s", err.Error())
+		}
+		err = models.InitTokenListVersion()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init token list version %s", err.Error())
+		}
+		err = models.InitEcosystemProfile()
+		if err != nil {
+			ExitCh <- fmt.Errorf("init ecosystem profile %s", err.Error())
+		}
 	}()
 	err := models.InitCountryLocator()
 	if err != nil {
@@ -0,0 +1,25 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/models"
+)
+
+// PendingTxStatusWork follow up the status of broadcast transactions
+func PendingTxStatusWork(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+			models.PendingTxStatusSync()
+		}
+	}
+}
@@ -630,6 +630,8 @@ LEFT JOIN(
 		da.BlockId = list[i].Block
 		da.Timestamp = MsToSeconds(list[i].Timestamp)
 		da.Address = converter.AddressToString(list[i].Address)
+		da.Iname = GetIName(da.Address)
+		da.Label = AddressLabels.Label(da.Address)
 		da.ContractName = list[i].ContractName
 		if da.ContractName == "" {
 			da.ContractName = GetUtxoTxContractNameByHash(list[i].Hash)
@@ -475,14 +475,8 @@ func GetEcosystemDetailMemberList(page, limit int, order string, ecosystem int64
 		Where("ecosystem = ?", ecosystem).
 		Order(order).Offset((page - 1) * limit).Limit(limit).
 		Find(&ret).Error
-	if INameReady {
-		for k, v := range ret {
-			ie := &IName{}
-			f, err := ie.Get(v.Account)
-			if err == nil && f {
-				ret[k].AccountName = ie.Name
-			}
-		}
+	for k, v := range ret {
+		ret[k].AccountName = GetIName(v.Account)
 	}
 	rets.List = ret
 
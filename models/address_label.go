@@ -0,0 +1,281 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	LabelExchange      = "exchange"
+	LabelBridge        = "bridge"
+	LabelFoundation    = "foundation"
+	LabelContract      = "contract"
+	LabelBurn          = "burn"
+	LabelDaoTreasury   = "dao_treasury"
+	LabelOther         = "other"
+	LabelSourceAdmin   = "admin"
+	LabelSourceYaml    = "yaml"
+	LabelSourceBuiltin = "builtin"
+
+	LabelUnverified = 0
+	LabelCommunity  = 1
+	LabelVerified   = 2
+	LabelOfficial   = 3
+
+	addressLabelFile = "labels.yml"
+)
+
+var labelCategories = map[string]bool{
+	LabelExchange: true, LabelBridge: true, LabelFoundation: true, LabelContract: true,
+	LabelBurn: true, LabelDaoTreasury: true, LabelOther: true,
+}
+
+// AddressLabel is a curated label of an account
+type AddressLabel struct {
+	ID          int64  `gorm:"primary_key;not null" json:"id"`
+	Account     string `gorm:"not null;uniqueIndex" json:"account" yaml:"account"`
+	Label       string `gorm:"not null" json:"label" yaml:"label"`
+	Category    string `gorm:"not null" json:"category" yaml:"category"`
+	Note        string `gorm:"not null" json:"note" yaml:"note"`
+	Source      string `gorm:"not null" json:"source" yaml:"source"`
+	VerifyLevel int    `gorm:"not null" json:"verify_level" yaml:"verify_level"` //0:unverified 1:community 2:verified 3:official
+	CreatedAt   int64  `gorm:"not null" json:"created_at" yaml:"-"`
+	UpdatedAt   int64  `gorm:"not null" json:"updated_at" yaml:"-"`
+}
+
+// AddressLabelInfo the label attached to account responses
+type AddressLabelInfo struct {
+	Label       string `json:"label"`
+	Category    string `json:"category"`
+	VerifyLevel int    `json:"verify_level"`
+}
+
+type addressLabelFileConf struct {
+	Labels []AddressLabel `yaml:"labels"`
+}
+
+type addressLabelCache struct {
+	m map[string]AddressLabelInfo
+	sync.RWMutex
+}
+
+var AddressLabels = &addressLabelCache{m: make(map[string]AddressLabelInfo)}
+
+func (p *AddressLabel) TableName() string {
+	return "address_label"
+}
+
+func (p *AddressLabel) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (p *AddressLabel) Get(account string) (bool, error) {
+	return isFound(GetDB(nil).Where("account = ?", account).Take(p))
+}
+
+// Validate normalizes the account and checks the category and verify level
+func (p *AddressLabel) Validate() error {
+	info := ValidateAddress(strings.TrimSpace(p.Account))
+	if !info.Valid {
+		return fmt.Errorf("account %s invalid: %s", p.Account, info.Message)
+	}
+	p.Account = info.Address
+	p.Label = strings.TrimSpace(p.Label)
+	if p.Label == "" {
+		return errors.New("label is empty")
+	}
+	if p.Category == "" {
+		p.Category = LabelOther
+	}
+	if !labelCategories[p.Category] {
+		return fmt.Errorf("label category %s invalid", p.Category)
+	}
+	if p.VerifyLevel < LabelUnverified || p.VerifyLevel > LabelOfficial {
+		return fmt.Errorf("verify level %d invalid", p.VerifyLevel)
+	}
+	return nil
+}
+
+// Label returns the label of the account, nil if the account has no label
+func (c *addressLabelCache) Label(account string) *AddressLabelInfo {
+	if c == nil || account == "" {
+		return nil
+	}
+	c.RLock()
+	defer c.RUnlock()
+	if v, ok := c.m[account]; ok {
+		return &v
+	}
+	return nil
+}
+
+func RefreshAddressLabelCache() error {
+	var list []AddressLabel
+	if err := GetDB(nil).Select("account,label,category,verify_level").Find(&list).Error; err != nil {
+		return err
+	}
+	m := make(map[string]AddressLabelInfo, len(list))
+	for _, v := range list {
+		m[v.Account] = AddressLabelInfo{Label: v.Label, Category: v.Category, VerifyLevel: v.VerifyLevel}
+	}
+	AddressLabels.Lock()
+	defer AddressLabels.Unlock()
+	AddressLabels.m = m
+	return nil
+}
+
+// SaveAddressLabels inserts or updates the labels by account
+func SaveAddressLabels(list []AddressLabel) error {
+	if len(list) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	for i := range list {
+		if err := list[i].Validate(); err != nil {
+			return err
+		}
+		list[i].ID = 0
+		list[i].CreatedAt = now
+		list[i].UpdatedAt = now
+	}
+	err := GetDB(nil).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account"}},
+		DoUpdates: clause.AssignmentColumns([]string{"label", "category", "note", "source", "verify_level", "updated_at"}),
+	}).CreateInBatches(&list, 500).Error
+	if err != nil {
+		return err
+	}
+	return RefreshAddressLabelCache()
+}
+
+func DeleteAddressLabel(account string) error {
+	info := ValidateAddress(account)
+	if !info.Valid {
+		return errors.New(info.Message)
+	}
+	if err := GetDB(nil).Where("account = ?", info.Address).Delete(&AddressLabel{}).Error; err != nil {
+		return err
+	}
+	return RefreshAddressLabelCache()
+}
+
+// ImportAddressLabelFile bulk imports labels.yml next to config.yml, the file is optional
+func ImportAddressLabelFile() (int, error) {
+	fileName := path.Join(conf.GetEnvConf().ConfigPath, addressLabelFile)
+	if ok, _ := conf.PathExists(fileName); !ok {
+		return 0, nil
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return 0, err
+	}
+	var fc addressLabelFileConf
+	if err = yaml.Unmarshal(data, &fc); err != nil {
+		return 0, err
+	}
+	for i := range fc.Labels {
+		if fc.Labels[i].Source == "" {
+			fc.Labels[i].Source = LabelSourceYaml
+		}
+	}
+	return len(fc.Labels), SaveAddressLabels(fc.Labels)
+}
+
+// builtinAddressLabels labels known from chain data: the burn address and the bridge wallets
+func builtinAddressLabels() []AddressLabel {
+	list := []AddressLabel{{
+		Account: BlackHoleAddr, Label: "Burn Address", Category: LabelBurn,
+		Source: LabelSourceBuiltin, VerifyLevel: LabelOfficial,
+	}}
+	if BridgeReady {
+		var bs []BridgeSettings
+		if err := GetDB(nil).Where("status = 1").Find(&bs).Error; err == nil {
+			for _, v := range bs {
+				if !ValidateAddress(v.BridgeAddress).Valid {
+					continue
+				}
+				list = append(list, AddressLabel{
+					Account: v.BridgeAddress, Label: v.BridgeName + " Bridge", Category: LabelBridge,
+					Note: v.ChainName, Source: LabelSourceBuiltin, VerifyLevel: LabelOfficial,
+				})
+			}
+		}
+	}
+	return list
+}
+
+// insertBuiltinAddressLabels adds the builtin labels missing, labels edited by admin are kept
+func insertBuiltinAddressLabels() error {
+	list := builtinAddressLabels()
+	now := time.Now().Unix()
+	for i := range list {
+		if err := list[i].Validate(); err != nil {
+			return err
+		}
+		list[i].CreatedAt = now
+		list[i].UpdatedAt = now
+	}
+	return GetDB(nil).Clauses(clause.OnConflict{DoNothing: true}).Create(&list).Error
+}
+
+func InitAddressLabel() error {
+	var p AddressLabel
+	if err := p.CreateTable(); err != nil {
+		return err
+	}
+	if err := insertBuiltinAddressLabels(); err != nil {
+		return err
+	}
+	if count, err := ImportAddressLabelFile(); err != nil {
+		log.WithFields(log.Fields{"error": err, "file": addressLabelFile}).Error("import address label file failed")
+	} else if count > 0 {
+		log.WithFields(log.Fields{"count": count}).Info("import address label file")
+	}
+	return RefreshAddressLabelCache()
+}
+
+func GetAddressLabelList(page, limit int, category, search string) (*GeneralResponse, error) {
+	var (
+		rets GeneralResponse
+		list []AddressLabel
+		p    AddressLabel
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Table(p.TableName())
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if search != "" {
+		query = query.Where("account = ? OR label ILIKE ?", search, "%"+search+"%")
+	}
+	if err := query.Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Order("verify_level desc,id asc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	rets.List = list
+	return &rets, nil
+}
@@ -0,0 +1,168 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/shopspring/decimal"
+)
+
+type AddressInfoResponse struct {
+	KeyId   string `json:"key_id"`
+	Address string `json:"address"`
+	Valid   bool   `json:"valid"`
+	Message string `json:"message,omitempty"`
+}
+
+type KeyEcosystemResponse struct {
+	Ecosystem     int64           `json:"ecosystem"`
+	EcosystemName string          `json:"ecosystem_name"`
+	TokenSymbol   string          `json:"token_symbol"`
+	Digits        int             `json:"digits"`
+	Amount        decimal.Decimal `json:"amount"`
+	Deleted       bool            `json:"deleted"`
+	Blocked       bool            `json:"blocked"`
+	Multi         bool            `json:"multi"`
+	PublicKey     string          `json:"public_key"`
+}
+
+// isAddressFormat account address is 20 digits and may be separated by '-'
+func isAddressFormat(address string) bool {
+	digits := strings.ReplaceAll(address, "-", "")
+	if len(digits) != 20 {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateAddress checks the format and checksum of account address
+func ValidateAddress(address string) AddressInfoResponse {
+	rets := AddressInfoResponse{Address: address}
+	if !isAddressFormat(address) {
+		rets.Message = "address must be 20 digits"
+		return rets
+	}
+	keyId := converter.StringToAddress(address)
+	if keyId == 0 && strings.ReplaceAll(address, "-", "") != strings.ReplaceAll(BlackHoleAddr, "-", "") {
+		rets.Message = "address checksum invalid"
+		return rets
+	}
+	rets.Valid = true
+	rets.KeyId = strconv.FormatInt(keyId, 10)
+	rets.Address = converter.AddressToString(keyId)
+	return rets
+}
+
+// ConvertAddress converts key id to address or address to key id. The key id may be negative or in the unsigned form,
+// the address is 20 digits and may be separated by '-'
+func ConvertAddress(value string) (AddressInfoResponse, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return AddressInfoResponse{}, errors.New("request params invalid")
+	}
+	//the 20 digits are the address even without '-', the checksum is verified
+	if isAddressFormat(value) {
+		rets := ValidateAddress(value)
+		if !rets.Valid {
+			return rets, errors.New(rets.Message)
+		}
+		return rets, nil
+	}
+	keyId, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		if strings.Contains(value, "-") {
+			rets := ValidateAddress(value)
+			return rets, errors.New(rets.Message)
+		}
+		uid, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return AddressInfoResponse{}, errors.New("value is neither key id nor address")
+		}
+		keyId = int64(uid)
+	}
+	return AddressInfoResponse{
+		KeyId:   strconv.FormatInt(keyId, 10),
+		Address: converter.AddressToString(keyId),
+		Valid:   true,
+	}, nil
+}
+
+// accountKeyId the key id of the account given as key id or address
+func accountKeyId(account string) (int64, error) {
+	info, err := ConvertAddress(account)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(info.KeyId, 10, 64)
+}
+
+// PublicKeyToAddress derives the account from the hex public key
+func PublicKeyToAddress(pubKey string) (AddressInfoResponse, error) {
+	pubKey = strings.TrimPrefix(strings.TrimSpace(pubKey), "0x")
+	if _, err := hex.DecodeString(pubKey); err != nil || pubKey == "" {
+		return AddressInfoResponse{}, errors.New("public key must be hex encoded")
+	}
+	keyId := smart.PubToID(pubKey)
+	if keyId == 0 {
+		return AddressInfoResponse{}, errors.New("public key invalid")
+	}
+	return AddressInfoResponse{
+		KeyId:   strconv.FormatInt(keyId, 10),
+		Address: converter.AddressToString(keyId),
+		Valid:   true,
+	}, nil
+}
+
+// GetKeyEcosystems list the ecosystems in which the key exists
+func GetKeyEcosystems(account string) ([]KeyEcosystemResponse, error) {
+	keyId, err := accountKeyId(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		list []Key
+		rets []KeyEcosystemResponse
+	)
+	err = GetDB(nil).Table(Key{}.TableName()).Select("ecosystem,id,pub,amount,multi,deleted,blocked").
+		Where("id = ?", keyId).Order("ecosystem asc").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		item := KeyEcosystemResponse{
+			Ecosystem:     v.Ecosystem,
+			EcosystemName: EcoNames.Get(v.Ecosystem),
+			TokenSymbol:   Tokens.Get(v.Ecosystem),
+			Digits:        EcoDigits.GetInt(v.Ecosystem, 0),
+			Amount:        v.Amount,
+			Deleted:       v.Deleted != 0,
+			Blocked:       v.Blocked != 0,
+			Multi:         v.Multi != 0,
+			PublicKey:     hex.EncodeToString(v.PublicKey),
+		}
+		if v.Ecosystem == 1 {
+			item.TokenSymbol = SysTokenSymbol
+			item.Digits = EcoDigits.GetInt(1, 12)
+			if item.EcosystemName == "" {
+				item.EcosystemName = SysEcosystemName
+			}
+		}
+		rets = append(rets, item)
+	}
+	return rets, nil
+}
@@ -0,0 +1,74 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+)
+
+func TestConvertAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		keyId   string
+		wantErr string
+	}{
+		{name: "positive key id", value: "123456", keyId: "123456"},
+		{name: "negative key id", value: "-123456", keyId: "-123456"},
+		{name: "negative key id with spaces", value: " -6097185355090423139 ", keyId: "-6097185355090423139"},
+		{name: "unsigned key id", value: "9223372036854775808", keyId: "-9223372036854775808"},
+		{name: "black hole address", value: BlackHoleAddr, keyId: "0"},
+		{name: "address", value: "0123-4567-8901-2345-6787", keyId: "1234567890123456787"},
+		{name: "undashed address", value: "01234567890123456787", keyId: "1234567890123456787"},
+		{name: "undashed address checksum", value: "01234567890123456789", wantErr: "address checksum invalid"},
+		{name: "unsigned key id of 20 digits", value: "18446744073709551615", wantErr: "address checksum invalid"},
+		{name: "short address", value: "1234-5678-9012", wantErr: "address must be 20 digits"},
+		{name: "not a number", value: "abc", wantErr: "value is neither key id nor address"},
+		{name: "empty", value: " ", wantErr: "request params invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rets, err := ConvertAddress(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !rets.Valid || rets.KeyId != tt.keyId {
+				t.Fatalf("key id = %s valid %v, want %s", rets.KeyId, rets.Valid, tt.keyId)
+			}
+			keyId, _ := strconv.ParseInt(tt.keyId, 10, 64)
+			if rets.Address != converter.AddressToString(keyId) {
+				t.Fatalf("address = %s, want %s", rets.Address, converter.AddressToString(keyId))
+			}
+		})
+	}
+}
+
+func TestIsAddressFormat(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{"0000-0000-0000-0000-0000", true},
+		{"00000000000000000000", true},
+		{"0000-0000-0000-0000-000", false},
+		{"0000-0000-0000-0000-000a", false},
+		{"-123456", false},
+	}
+	for _, tt := range tests {
+		if got := isAddressFormat(tt.address); got != tt.want {
+			t.Errorf("isAddressFormat(%q) = %v, want %v", tt.address, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/common/crypto"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	IntegrityMissingBlock  = "missing_block"
+	IntegrityUnmarshal     = "unmarshal"
+	IntegrityBlockHash     = "block_hash"
+	IntegrityPrevHash      = "prev_hash"
+	IntegritySignature     = "signature"
+	IntegrityUnknownKey    = "unknown_key"
+	IntegrityRollbacksHash = "rollbacks_hash"
+
+	IntegrityLevelError = 1
+	IntegrityLevelWarn  = 2
+
+	blockIntegrityMax = "block_integrity_max"
+	//integrityBatch blocks checked in one round of the background job
+	integrityBatch = 1000
+)
+
+// BlockIntegrityReport is a finding of the chain verifier
+type BlockIntegrityReport struct {
+	ID        int64  `gorm:"primary_key;not null" json:"id"`
+	BlockId   int64  `gorm:"not null;index" json:"block_id"`
+	CheckType string `gorm:"not null" json:"check_type"`
+	Level     int    `gorm:"not null" json:"level"` //1:error 2:warn
+	Expected  string `gorm:"not null" json:"expected"`
+	Actual    string `gorm:"not null" json:"actual"`
+	Detail    string `gorm:"not null" json:"detail"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+type VerifyChainResult struct {
+	From     int64 `json:"from"`
+	To       int64 `json:"to"`
+	Checked  int64 `json:"checked"`
+	Errors   int64 `json:"errors"`
+	Warnings int64 `json:"warnings"`
+}
+
+type BlockIntegritySummary struct {
+	LastChecked int64 `json:"last_checked"`
+	MaxBlockId  int64 `json:"max_block_id"`
+	Errors      int64 `json:"errors"`
+	Warnings    int64 `json:"warnings"`
+}
+
+func (p *BlockIntegrityReport) TableName() string {
+	return "block_integrity_report"
+}
+
+func (p *BlockIntegrityReport) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func InitBlockIntegrityReport() error {
+	var p BlockIntegrityReport
+	return p.CreateTable()
+}
+
+// honorNodeChange a change of the honor_nodes platform parameter, the old value is in effect up to the block of the change
+type honorNodeChange struct {
+	Block int64
+	Keys  map[int64][]byte
+}
+
+// nodePubKeys caches the node public keys for signature checks. The honor nodes are indexed by node position
+// in the honor_nodes in effect at the block: the old value of the first later change, or the current value
+type nodePubKeys struct {
+	changes   []honorNodeChange //ordered by block
+	honor     map[int64][]byte  //the current honor nodes
+	candidate map[int64][]byte
+}
+
+var blockIntegrityLock sync.Mutex
+
+func parseHonorNodeKeys(value string) (map[int64][]byte, error) {
+	var nodes []NodeInfo
+	keys := make(map[int64][]byte)
+	if value == "" {
+		return keys, nil
+	}
+	if err := json.Unmarshal([]byte(value), &nodes); err != nil {
+		return nil, err
+	}
+	for key, node := range nodes {
+		if pub, err := hex.DecodeString(node.PublicKey); err == nil {
+			keys[int64(key)] = pub
+		}
+	}
+	return keys, nil
+}
+
+func newNodePubKeys() (*nodePubKeys, error) {
+	keys := &nodePubKeys{
+		honor:     make(map[int64][]byte),
+		candidate: make(map[int64][]byte),
+	}
+	var (
+		sp    PlatformParameter
+		param struct {
+			Id    int64
+			Value string
+		}
+	)
+	f, err := isFound(GetDB(nil).Table(sp.TableName()).Where("name = ?", "honor_nodes").Select("id,value").Take(&param))
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return keys, nil
+	}
+	if keys.honor, err = parseHonorNodeKeys(param.Value); err != nil {
+		return nil, err
+	}
+
+	type rollback struct {
+		BlockId int64
+		Data    string
+	}
+	var list []rollback
+	err = GetDB(nil).Table("rollback_tx").Select("block_id,data").
+		Where("table_name = ? AND SPLIT_PART(table_id,',',1) = ?", platformParameterTable, strconv.FormatInt(param.Id, 10)).
+		Order("id asc").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		if v.Data == "" {
+			continue
+		}
+		var data map[string]any
+		if err = json.Unmarshal([]byte(v.Data), &data); err != nil {
+			return nil, err
+		}
+		val, ok := data["value"]
+		if !ok {
+			//only the conditions changed
+			continue
+		}
+		nodes, err := parseHonorNodeKeys(parameterValueString(val))
+		if err != nil {
+			return nil, err
+		}
+		keys.changes = append(keys.changes, honorNodeChange{Block: v.BlockId, Keys: nodes})
+	}
+	return keys, nil
+}
+
+// honorAt the honor node keys in effect when the block was generated
+func (k *nodePubKeys) honorAt(blockId int64) map[int64][]byte {
+	i := sort.Search(len(k.changes), func(i int) bool {
+		return k.changes[i].Block >= blockId
+	})
+	if i < len(k.changes) {
+		return k.changes[i].Keys
+	}
+	return k.honor
+}
+
+func (k *nodePubKeys) get(blockId int64, consensusMode int32, nodePosition int64) []byte {
+	if consensusMode != 2 {
+		return k.honorAt(blockId)[nodePosition]
+	}
+	if pub, ok := k.candidate[nodePosition]; ok {
+		return pub
+	}
+	var can CandidateNodeRequests
+	f, err := can.GetPubKeyById(nodePosition)
+	if err != nil || !f {
+		return nil
+	}
+	pub, err := hex.DecodeString(can.NodePubKey)
+	if err != nil {
+		return nil
+	}
+	k.candidate[nodePosition] = pub
+	return pub
+}
+
+func newIntegrityReport(blockId int64, checkType string, level int, expected, actual []byte, detail string) BlockIntegrityReport {
+	return BlockIntegrityReport{
+		BlockId:   blockId,
+		CheckType: checkType,
+		Level:     level,
+		Expected:  hex.EncodeToString(expected),
+		Actual:    hex.EncodeToString(actual),
+		Detail:    detail,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+// verifyBlock re-checks one block_chain row against the previous row
+func verifyBlock(bk, prev *Block, keys *nodePubKeys) []BlockIntegrityReport {
+	var rlt []BlockIntegrityReport
+	blck, err := block.UnmarshallBlock(bytes.NewBuffer(bk.Data), false)
+	if err != nil {
+		return append(rlt, newIntegrityReport(bk.ID, IntegrityUnmarshal, IntegrityLevelError, nil, nil, err.Error()))
+	}
+	if !bytes.Equal(blck.Header.BlockHash, bk.Hash) {
+		rlt = append(rlt, newIntegrityReport(bk.ID, IntegrityBlockHash, IntegrityLevelError, blck.Header.BlockHash, bk.Hash, "block_chain hash differs from block header hash"))
+	}
+	if len(blck.Header.RollbacksHash) > 0 && !bytes.Equal(blck.Header.RollbacksHash, bk.RollbacksHash) {
+		rlt = append(rlt, newIntegrityReport(bk.ID, IntegrityRollbacksHash, IntegrityLevelError, blck.Header.RollbacksHash, bk.RollbacksHash, "block_chain rollbacks_hash differs from block header"))
+	}
+	if prev != nil {
+		if blck.PrevHeader != nil && !bytes.Equal(blck.PrevHeader.BlockHash, prev.Hash) {
+			rlt = append(rlt, newIntegrityReport(bk.ID, IntegrityPrevHash, IntegrityLevelError, prev.Hash, blck.PrevHeader.BlockHash, "previous hash doesn't match previous block"))
+		}
+		if bytes.Equal(bk.RollbacksHash, prev.RollbacksHash) && bk.Tx > 0 {
+			rlt = append(rlt, newIntegrityReport(bk.ID, IntegrityRollbacksHash, IntegrityLevelWarn, prev.RollbacksHash, bk.RollbacksHash, "rollbacks_hash unchanged although block has transactions"))
+		}
+	}
+
+	if bk.ID > 1 && blck.PrevHeader != nil {
+		pub := keys.get(bk.ID, blck.Header.ConsensusMode, blck.Header.NodePosition)
+		if pub == nil {
+			rlt = append(rlt, newIntegrityReport(bk.ID, IntegrityUnknownKey, IntegrityLevelWarn, nil, nil,
+				fmt.Sprintf("public key of node position %d consensus mode %d not found", blck.Header.NodePosition, blck.Header.ConsensusMode)))
+		} else {
+			forSign := blck.Header.ForSign(blck.PrevHeader, blck.MerkleRoot)
+			ok, err := crypto.Verify(pub, []byte(forSign), blck.Header.Sign)
+			if err != nil || !ok {
+				detail := "block signature invalid"
+				if err != nil {
+					detail = err.Error()
+				}
+				rlt = append(rlt, newIntegrityReport(bk.ID, IntegritySignature, IntegrityLevelError, pub, blck.Header.Sign, detail))
+			}
+		}
+	}
+	return rlt
+}
+
+// VerifyChain re-checks the blocks in [from,to] and stores the findings. to <= 0 means the max block
+func VerifyChain(from, to int64) (*VerifyChainResult, error) {
+	if from < 1 {
+		from = 1
+	}
+	if to <= 0 {
+		var bk Block
+		f, err := bk.GetMaxBlock()
+		if err != nil {
+			return nil, err
+		}
+		if !f {
+			return nil, fmt.Errorf("block chain is empty")
+		}
+		to = bk.ID
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range from %d to %d", from, to)
+	}
+	rets := &VerifyChainResult{From: from, To: to}
+	keys, err := newNodePubKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var prev *Block
+	if from > 1 {
+		var bk Block
+		f, err := bk.GetId(from - 1)
+		if err != nil {
+			return nil, err
+		}
+		if f {
+			prev = &bk
+		}
+	}
+	for start := from - 1; start < to; start += integrityBatch {
+		end := start + integrityBatch
+		if end > to {
+			end = to
+		}
+		var list []Block
+		err := GetDB(nil).Select("id,hash,rollbacks_hash,data,tx").Where("id > ? AND id <= ?", start, end).Order("id asc").Find(&list).Error
+		if err != nil {
+			return nil, err
+		}
+		var reports []BlockIntegrityReport
+		expectId := start + 1
+		for i := range list {
+			bk := &list[i]
+			for ; expectId < bk.ID; expectId++ {
+				reports = append(reports, newIntegrityReport(expectId, IntegrityMissingBlock, IntegrityLevelError, nil, nil, "block doesn't not exist in block_chain"))
+				prev = nil
+			}
+			reports = append(reports, verifyBlock(bk, prev, keys)...)
+			prev = bk
+			expectId = bk.ID + 1
+			rets.Checked++
+		}
+		for ; expectId <= end; expectId++ {
+			reports = append(reports, newIntegrityReport(expectId, IntegrityMissingBlock, IntegrityLevelError, nil, nil, "block doesn't not exist in block_chain"))
+			prev = nil
+		}
+		for _, v := range reports {
+			if v.Level == IntegrityLevelError {
+				rets.Errors++
+			} else {
+				rets.Warnings++
+			}
+		}
+		if err = saveIntegrityReports(start+1, end, reports); err != nil {
+			return nil, err
+		}
+	}
+	return rets, nil
+}
+
+// saveIntegrityReports replaces the findings of the checked range so re-runs don't duplicate rows
+func saveIntegrityReports(from, to int64, reports []BlockIntegrityReport) error {
+	return GetDB(nil).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("block_id >= ? AND block_id <= ?", from, to).Delete(&BlockIntegrityReport{}).Error; err != nil {
+			return err
+		}
+		if len(reports) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&reports, 1000).Error
+	})
+}
+
+// BlockIntegritySync incremental check of new blocks, run by the history crontab
+func BlockIntegritySync() {
+	if !blockIntegrityLock.TryLock() {
+		return
+	}
+	defer blockIntegrityLock.Unlock()
+	var (
+		bk   Block
+		last BlockID
+	)
+	f, err := bk.GetMaxBlock()
+	if err != nil || !f {
+		return
+	}
+	if f, err = last.GetByName(blockIntegrityMax); err != nil || !f {
+		last.ID = 0
+	}
+	if last.ID >= bk.ID {
+		return
+	}
+	to := last.ID + integrityBatch
+	if to > bk.ID {
+		to = bk.ID
+	}
+	rlt, err := VerifyChain(last.ID+1, to)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "from": last.ID + 1, "to": to}).Error("block integrity sync failed")
+		return
+	}
+	if rlt.Errors > 0 {
+		log.WithFields(log.Fields{"from": rlt.From, "to": rlt.To, "errors": rlt.Errors}).Warn("block integrity check found errors")
+	}
+	last.ID = to
+	last.Name = blockIntegrityMax
+	last.Time = time.Now().Unix()
+	if err = last.InsertRedis(); err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("block integrity sync insert redis failed")
+	}
+}
+
+func GetBlockIntegrityReport(page, limit int, level int, blockId int64) (*GeneralResponse, error) {
+	var (
+		rets GeneralResponse
+		list []BlockIntegrityReport
+		p    BlockIntegrityReport
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Table(p.TableName())
+	if level > 0 {
+		query = query.Where("level = ?", level)
+	}
+	if blockId > 0 {
+		query = query.Where("block_id = ?", blockId)
+	}
+	if err := query.Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Order("block_id desc,id desc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	rets.List = list
+	return &rets, nil
+}
+
+func GetBlockIntegritySummary() (*BlockIntegritySummary, error) {
+	var (
+		rets BlockIntegritySummary
+		bk   Block
+		last BlockID
+		p    BlockIntegrityReport
+	)
+	f, err := bk.GetMaxBlock()
+	if err != nil {
+		return nil, err
+	}
+	if f {
+		rets.MaxBlockId = bk.ID
+	}
+	if f, err = last.GetByName(blockIntegrityMax); err == nil && f {
+		rets.LastChecked = last.ID
+	}
+	if err = GetDB(nil).Table(p.TableName()).Where("level = ?", IntegrityLevelError).Count(&rets.Errors).Error; err != nil {
+		return nil, err
+	}
+	if err = GetDB(nil).Table(p.TableName()).Where("level = ?", IntegrityLevelWarn).Count(&rets.Warnings).Error; err != nil {
+		return nil, err
+	}
+	return &rets, nil
+}
@@ -0,0 +1,60 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseHonorNodeKeys(t *testing.T) {
+	keys, err := parseHonorNodeKeys(`[{"public_key":"0a0b"},{"public_key":"zz"},{"public_key":"0c"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || !bytes.Equal(keys[0], []byte{0x0a, 0x0b}) || !bytes.Equal(keys[2], []byte{0x0c}) {
+		t.Fatalf("keys = %v", keys)
+	}
+	if keys, err = parseHonorNodeKeys(""); err != nil || len(keys) != 0 {
+		t.Fatalf("empty value = %v, %v", keys, err)
+	}
+	if _, err = parseHonorNodeKeys("{"); err == nil {
+		t.Fatal("expected error for the invalid value")
+	}
+}
+
+func TestNodePubKeysHonorAt(t *testing.T) {
+	set := func(b byte) map[int64][]byte {
+		return map[int64][]byte{0: {b}}
+	}
+	keys := &nodePubKeys{
+		changes: []honorNodeChange{
+			{Block: 100, Keys: set(1)},
+			{Block: 100, Keys: set(2)},
+			{Block: 250, Keys: set(3)},
+		},
+		honor:     set(4),
+		candidate: make(map[int64][]byte),
+	}
+	tests := []struct {
+		block int64
+		want  byte
+	}{
+		{block: 1, want: 1},
+		{block: 100, want: 1},
+		{block: 101, want: 3},
+		{block: 250, want: 3},
+		{block: 251, want: 4},
+	}
+	for _, tt := range tests {
+		if got := keys.get(tt.block, 1, 0); !bytes.Equal(got, []byte{tt.want}) {
+			t.Errorf("key at block %d = %v, want %d", tt.block, got, tt.want)
+		}
+	}
+	if got := keys.get(251, 1, 1); got != nil {
+		t.Errorf("unknown position = %v", got)
+	}
+}
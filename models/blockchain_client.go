@@ -10,19 +10,32 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
+// nodeRequestTimeout the timeout of a request to the node api
+const nodeRequestTimeout = 30 * time.Second
+
+var nodeClient = &http.Client{Timeout: nodeRequestTimeout}
+
 func sendPostFormRequest(reqUrl string, reqBody url.Values) ([]byte, error) {
-	resp, err := http.PostForm(reqUrl, reqBody)
+	_, data, err := sendPostForm(reqUrl, reqBody)
+	return data, err
+}
+
+// sendPostForm posts the form to the node api, returns the http status code and the body
+func sendPostForm(reqUrl string, reqBody url.Values) (int, []byte, error) {
+	resp, err := nodeClient.Post(reqUrl, "application/x-www-form-urlencoded", strings.NewReader(reqBody.Encode()))
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("sendPostFormRequest err:")
-		return nil, err
+		return resp.StatusCode, nil, err
 	}
-	return data, nil
+	return resp.StatusCode, data, nil
 }
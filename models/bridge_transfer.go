@@ -0,0 +1,586 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	BridgeDeposit  = "deposit"
+	BridgeWithdraw = "withdraw"
+
+	BridgeStatusConfirming = "confirming"
+	BridgeStatusApproving  = "approving"
+	BridgeStatusConfirmed  = "confirmed"
+	BridgeStatusApproved   = "approved"
+
+	bridgeChartMaxDays       = 365
+	bridgePendingDefaultDays = 7
+	BridgePendingMaxDays     = 30
+	bridgeApprovalBatch      = 1000
+)
+
+// bridgeTransferSQL the transfers of the bridge tokens from and to the bridge wallet: the wallet sends the deposits of the
+// other chain to the accounts, the accounts send the withdrawals to the wallet. The fees and taxes(type 1,2) are excluded
+const bridgeTransferSQL = `(
+	SELECT h.txhash AS hash,h.block_id AS block,h.created_at,h.ecosystem,h.amount,
+		CASE WHEN h.recipient_id = @wallet THEN 'withdraw' ELSE 'deposit' END AS type,
+		CASE WHEN h.recipient_id = @wallet THEN h.sender_id ELSE h.recipient_id END AS key_id
+	FROM "1_history" AS h
+	WHERE h.type NOT IN(1,2) AND h.ecosystem IN(@ecosystems) AND (h.sender_id = @wallet OR h.recipient_id = @wallet) AND h.sender_id <> h.recipient_id
+)`
+
+type BridgeTransfer struct {
+	Hash                  string          `json:"hash"`
+	Block                 int64           `json:"block"`
+	CreatedAt             int64           `json:"created_at"`
+	Type                  string          `json:"type"`
+	SettingId             int64           `json:"setting_id"`
+	BridgeName            string          `json:"bridge_name"`
+	ChainName             string          `json:"chain_name"`
+	ChainId               int64           `json:"chain_id"`
+	Account               string          `json:"account"`
+	Ecosystem             int64           `json:"ecosystem"`
+	TokenSymbol           string          `json:"token_symbol"`
+	TokenAddress          string          `json:"token_address"`
+	Digits                int64           `json:"digits"`
+	Amount                decimal.Decimal `json:"amount"`
+	IbaxConfirmations     int64           `json:"ibax_confirmations"`     //IBAX blocks on top of the transfer block
+	RequiredConfirmations int64           `json:"required_confirmations"` //DepositConfirm or WithdrawConfirm of the bridge
+	Approvals             int64           `json:"approvals"`              //owner approvals of the withdrawal
+	RequiredApprovals     int64           `json:"required_approvals"`     //0 for the deposits
+	Status                string          `json:"status"`
+}
+
+type BridgeTransferFind struct {
+	SettingId int64  `json:"setting_id"`
+	Type      string `json:"type"`
+	Account   string `json:"account"`
+	Hash      string `json:"hash"`
+	Days      int    `json:"days"` //the window of the pending withdrawals, 7 days by default
+	Page      int    `json:"page"`
+	Limit     int    `json:"limit"`
+}
+
+type BridgeVolumeToken struct {
+	Ecosystem   int64    `json:"ecosystem"`
+	TokenSymbol string   `json:"token_symbol"`
+	Digits      int64    `json:"digits"`
+	Deposit     []string `json:"deposit"`
+	Withdraw    []string `json:"withdraw"`
+}
+
+type BridgeVolumeChartResponse struct {
+	SettingId  int64               `json:"setting_id"`
+	BridgeName string              `json:"bridge_name"`
+	ChainName  string              `json:"chain_name"`
+	Time       []int64             `json:"time"`
+	Tokens     []BridgeVolumeToken `json:"tokens"`
+}
+
+type bridgeTransferRow struct {
+	SettingId int64
+	Hash      []byte
+	Block     int64
+	CreatedAt int64
+	Ecosystem int64
+	Amount    decimal.Decimal
+	Type      string
+	KeyId     int64
+}
+
+type bridgeInstance struct {
+	setting BridgeSettings
+	wallet  int64
+	owners  []int64
+	tokens  map[int64]BridgeToken
+}
+
+func (b *bridgeInstance) ecosystems() []int64 {
+	var list []int64
+	for eco := range b.tokens {
+		list = append(list, eco)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+	return list
+}
+
+// bridgeOwners the owner key ids of the bridge, the owners are a json array or a comma separated list of the addresses
+func bridgeOwners(owners string) []int64 {
+	var list []string
+	var values []any
+	if err := json.Unmarshal([]byte(owners), &values); err == nil {
+		for _, v := range values {
+			switch val := v.(type) {
+			case string:
+				list = append(list, val)
+			case float64:
+				list = append(list, strconv.FormatInt(int64(val), 10))
+			}
+		}
+	} else {
+		list = strings.Split(owners, ",")
+	}
+	var rets []int64
+	for _, v := range list {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.Contains(v, "-") {
+			rets = append(rets, converter.StringToAddress(v))
+		} else {
+			rets = append(rets, converter.StrToInt64(v))
+		}
+	}
+	return rets
+}
+
+func getBridgeInstances(settingId int64) ([]bridgeInstance, error) {
+	if !BridgeReady {
+		return nil, errors.New("bridge doesn't not exist")
+	}
+	var settings []BridgeSettings
+	query := GetDB(nil).Where("status = 1")
+	if settingId > 0 {
+		query = query.Where("id = ?", settingId)
+	}
+	if err := query.Order("id asc").Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	if settingId > 0 && len(settings) == 0 {
+		return nil, errors.New("bridge doesn't not exist")
+	}
+	var rets []bridgeInstance
+	for _, s := range settings {
+		var tokens []BridgeToken
+		if err := GetDB(nil).Where("setting_id = ? AND status = 1", s.Id).Find(&tokens).Error; err != nil {
+			return nil, err
+		}
+		ins := bridgeInstance{
+			setting: s,
+			wallet:  converter.StringToAddress(s.BridgeAddress),
+			owners:  bridgeOwners(s.Owners),
+			tokens:  make(map[int64]BridgeToken),
+		}
+		for _, t := range tokens {
+			ins.tokens[t.Ecosystem] = t
+		}
+		if ins.wallet == 0 || len(ins.tokens) == 0 {
+			continue
+		}
+		rets = append(rets, ins)
+	}
+	return rets, nil
+}
+
+func getBridgeMaxBlock() (int64, error) {
+	var bk Block
+	f, err := bk.GetMaxBlock()
+	if err != nil {
+		return 0, err
+	}
+	if !f {
+		return 0, nil
+	}
+	return bk.ID, nil
+}
+
+// bridgeApprovalWindow the owners approve a withdrawal within the pending window, the later owner transactions aren't scanned
+const bridgeApprovalWindow = int64(BridgePendingMaxDays) * 24 * 60 * 60 * 1000
+
+// bridgeApprovalRanges the merged time ranges(ms) of the approval windows of the withdrawals, sorted by the start
+func bridgeApprovalRanges(withdrawals []bridgeTransferRow) [][2]int64 {
+	var ranges [][2]int64
+	for _, v := range withdrawals {
+		ranges = append(ranges, [2]int64{v.CreatedAt, v.CreatedAt + bridgeApprovalWindow})
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+	var rets [][2]int64
+	for _, v := range ranges {
+		if n := len(rets); n > 0 && v[0] <= rets[n-1][1] {
+			if v[1] > rets[n-1][1] {
+				rets[n-1][1] = v[1]
+			}
+			continue
+		}
+		rets = append(rets, v)
+	}
+	return rets
+}
+
+// getBridgeApprovals the number of owners that approved each withdrawal: the owner transactions of the bridge contracts
+// in the approval window of the withdrawals, whose params carry the withdrawal hash. The owner transactions are loaded
+// and decoded once for all the withdrawals
+func getBridgeApprovals(owners []int64, withdrawals []bridgeTransferRow) (map[string]int64, error) {
+	rets := make(map[string]int64)
+	if len(owners) == 0 || len(withdrawals) == 0 {
+		return rets, nil
+	}
+	var (
+		conds []string
+		args  []any
+	)
+	for _, v := range bridgeApprovalRanges(withdrawals) {
+		conds = append(conds, "(timestamp >= ? AND timestamp < ?)")
+		args = append(args, v[0], v[1])
+	}
+	var list []LogTransaction
+	err := GetDB(nil).Select("hash,address,block,timestamp").Where("address IN(?) AND contract_name ILIKE ?", owners, "%bridge%").
+		Where(strings.Join(conds, " OR "), args...).Order("block asc").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	approved := make(map[string]map[int64]bool)
+	for start := 0; start < len(list); start += bridgeApprovalBatch {
+		end := start + bridgeApprovalBatch
+		if end > len(list) {
+			end = len(list)
+		}
+		batch := list[start:end]
+		hashes := make([][]byte, 0, len(batch))
+		for _, v := range batch {
+			hashes = append(hashes, v.Hash)
+		}
+		var data []TransactionData
+		if err = GetDB(nil).Select("hash,tx_data").Where("hash IN(?)", hashes).Find(&data).Error; err != nil {
+			return nil, err
+		}
+		txData := make(map[string][]byte, len(data))
+		for _, v := range data {
+			txData[string(v.Hash)] = v.TxData
+		}
+		for i := range batch {
+			v := &batch[i]
+			td, ok := txData[string(v.Hash)]
+			if !ok {
+				continue
+			}
+			info, err := v.UnmarshalTransaction(td)
+			if err != nil || info == nil {
+				continue
+			}
+			params := strings.ToLower(info.Params)
+			for _, w := range withdrawals {
+				hash := hex.EncodeToString(w.Hash)
+				if w.Block > v.Block || v.Timestamp >= w.CreatedAt+bridgeApprovalWindow || !strings.Contains(params, hash) {
+					continue
+				}
+				if approved[hash] == nil {
+					approved[hash] = make(map[int64]bool)
+				}
+				approved[hash][v.Address] = true
+			}
+		}
+	}
+	for hash, v := range approved {
+		rets[hash] = int64(len(v))
+	}
+	return rets, nil
+}
+
+// transfer the record of the row. Only the IBAX side of the bridge is indexed, so the confirmations are the IBAX blocks
+// on top of the transfer. They are compared with the bridge thresholds as a lower bound: the blocks of the other chain
+// aren't known, and a transfer isn't confirmed until its IBAX tx has the required depth
+func (b *bridgeInstance) transfer(v bridgeTransferRow, maxBlock int64, approvals map[string]int64) BridgeTransfer {
+	token := b.tokens[v.Ecosystem]
+	item := BridgeTransfer{
+		Hash:         hex.EncodeToString(v.Hash),
+		Block:        v.Block,
+		CreatedAt:    MsToSeconds(v.CreatedAt),
+		Type:         v.Type,
+		SettingId:    b.setting.Id,
+		BridgeName:   b.setting.BridgeName,
+		ChainName:    b.setting.ChainName,
+		ChainId:      b.setting.ChainId,
+		Account:      converter.AddressToString(v.KeyId),
+		Ecosystem:    v.Ecosystem,
+		TokenSymbol:  token.TokenSymbol,
+		TokenAddress: token.TokenAddress,
+		Digits:       EcoDigits.GetInt64(v.Ecosystem, token.TokenDigits),
+		Amount:       v.Amount,
+	}
+	if maxBlock >= v.Block {
+		item.IbaxConfirmations = maxBlock - v.Block
+	}
+	if v.Type == BridgeDeposit {
+		item.RequiredConfirmations = b.setting.DepositConfirm
+		item.Status = BridgeStatusConfirmed
+		if item.IbaxConfirmations < item.RequiredConfirmations {
+			item.Status = BridgeStatusConfirming
+		}
+		return item
+	}
+	item.RequiredConfirmations = b.setting.WithdrawConfirm
+	item.RequiredApprovals = b.setting.Required
+	item.Approvals = approvals[item.Hash]
+	switch {
+	case item.IbaxConfirmations < item.RequiredConfirmations:
+		item.Status = BridgeStatusConfirming
+	case item.Approvals < item.RequiredApprovals:
+		item.Status = BridgeStatusApproving
+	default:
+		item.Status = BridgeStatusApproved
+	}
+	return item
+}
+
+// transfers the records of the rows, the approvals of the withdrawals are looked up in one batch
+func (b *bridgeInstance) transfers(list []bridgeTransferRow, maxBlock int64) ([]BridgeTransfer, error) {
+	var withdrawals []bridgeTransferRow
+	for _, v := range list {
+		if v.Type == BridgeWithdraw {
+			withdrawals = append(withdrawals, v)
+		}
+	}
+	approvals, err := getBridgeApprovals(b.owners, withdrawals)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "setting": b.setting.Id}).Error("Get Bridge Approvals Failed")
+		return nil, err
+	}
+	items := make([]BridgeTransfer, 0, len(list))
+	for _, v := range list {
+		items = append(items, b.transfer(v, maxBlock, approvals))
+	}
+	return items, nil
+}
+
+func bridgeTransferArgs(b *bridgeInstance) map[string]any {
+	return map[string]any{"wallet": b.wallet, "ecosystems": b.ecosystems()}
+}
+
+// GetBridgeTransfers the deposit and withdrawal records of the bridges
+func GetBridgeTransfers(req *BridgeTransferFind) (*GeneralResponse, error) {
+	var rets GeneralResponse
+	rets.Page = req.Page
+	rets.Limit = req.Limit
+	if req.Type != "" && req.Type != BridgeDeposit && req.Type != BridgeWithdraw {
+		return nil, errors.New("type invalid")
+	}
+	bridges, err := getBridgeInstances(req.SettingId)
+	if err != nil {
+		return nil, err
+	}
+	maxBlock, err := getBridgeMaxBlock()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		sqls  []string
+		where []string
+		args  = make(map[string]any)
+		index = make(map[int64]*bridgeInstance)
+	)
+	for i := range bridges {
+		b := &bridges[i]
+		id := strconv.FormatInt(b.setting.Id, 10)
+		sql := strings.NewReplacer("@wallet", "@wallet"+id, "@ecosystems", "@ecosystems"+id).Replace(bridgeTransferSQL)
+		sqls = append(sqls, "SELECT "+id+" AS setting_id,* FROM "+sql+" AS b"+id)
+		for k, v := range bridgeTransferArgs(b) {
+			args[k+id] = v
+		}
+		index[b.setting.Id] = b
+	}
+	if len(sqls) == 0 {
+		rets.List = []BridgeTransfer{}
+		return &rets, nil
+	}
+	if req.Type != "" {
+		where = append(where, "type = @type")
+		args["type"] = req.Type
+	}
+	if req.Account != "" {
+		where = append(where, "key_id = @key_id")
+		args["key_id"] = converter.StringToAddress(req.Account)
+	}
+	if req.Hash != "" {
+		hash, err := hex.DecodeString(req.Hash)
+		if err != nil {
+			return nil, errors.New("hash invalid")
+		}
+		where = append(where, "hash = @hash")
+		args["hash"] = hash
+	}
+	from := "(" + strings.Join(sqls, " UNION ALL ") + ") AS v1"
+	if len(where) > 0 {
+		from += " WHERE " + strings.Join(where, " AND ")
+	}
+	if err = GetDB(nil).Raw("SELECT count(1) FROM "+from, args).Take(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	args["offset"] = (req.Page - 1) * req.Limit
+	args["limit"] = req.Limit
+	var list []bridgeTransferRow
+	err = GetDB(nil).Raw("SELECT * FROM "+from+" ORDER BY block DESC,hash ASC OFFSET @offset LIMIT @limit", args).Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Get Bridge Transfers Failed")
+		return nil, err
+	}
+	//the rows of each bridge, the positions keep the order of the list
+	rows := make(map[int64][]bridgeTransferRow)
+	positions := make(map[int64][]int)
+	for i, v := range list {
+		rows[v.SettingId] = append(rows[v.SettingId], v)
+		positions[v.SettingId] = append(positions[v.SettingId], i)
+	}
+	items := make([]BridgeTransfer, len(list))
+	found := make([]bool, len(list))
+	for id, v := range rows {
+		b, ok := index[id]
+		if !ok {
+			continue
+		}
+		transfers, err := b.transfers(v, maxBlock)
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range transfers {
+			items[positions[id][i]] = item
+			found[positions[id][i]] = true
+		}
+	}
+	rlt := make([]BridgeTransfer, 0, len(list))
+	for i, item := range items {
+		if found[i] {
+			rlt = append(rlt, item)
+		}
+	}
+	items = rlt
+	rets.List = items
+	return &rets, nil
+}
+
+// GetBridgePendingWithdrawals the withdrawals of the last days that wait for the confirmations or the owner approvals.
+// All the withdrawals of the window are checked, so the total is the count of the pending withdrawals in the window
+func GetBridgePendingWithdrawals(settingId int64, days, page, limit int) (*GeneralResponse, error) {
+	var rets GeneralResponse
+	rets.Page = page
+	rets.Limit = limit
+	if days == 0 {
+		days = bridgePendingDefaultDays
+	}
+	if days < 0 || days > BridgePendingMaxDays {
+		return nil, errors.New("days invalid")
+	}
+	bridges, err := getBridgeInstances(settingId)
+	if err != nil {
+		return nil, err
+	}
+	maxBlock, err := getBridgeMaxBlock()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now().AddDate(0, 0, -days).UnixMilli()
+	var pending []BridgeTransfer
+	for i := range bridges {
+		b := &bridges[i]
+		var list []bridgeTransferRow
+		args := bridgeTransferArgs(b)
+		args["type"] = BridgeWithdraw
+		args["start"] = start
+		err = GetDB(nil).Raw("SELECT * FROM "+bridgeTransferSQL+" AS v1 WHERE type = @type AND created_at >= @start ORDER BY block DESC,hash ASC", args).
+			Find(&list).Error
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "setting": b.setting.Id}).Error("Get Bridge Pending Withdrawals Failed")
+			return nil, err
+		}
+		items, err := b.transfers(list, maxBlock)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Status != BridgeStatusApproved {
+				pending = append(pending, item)
+			}
+		}
+	}
+	rets.Total = int64(len(pending))
+	first := (page - 1) * limit
+	if first > len(pending) {
+		first = len(pending)
+	}
+	last := first + limit
+	if last > len(pending) {
+		last = len(pending)
+	}
+	rets.List = pending[first:last]
+	return &rets, nil
+}
+
+// GetBridgeVolumeChart the daily deposit and withdrawal volume of each token of the bridge
+func GetBridgeVolumeChart(settingId int64, days int) (*BridgeVolumeChartResponse, error) {
+	if days <= 0 || days > bridgeChartMaxDays {
+		return nil, errors.New("days invalid")
+	}
+	bridges, err := getBridgeInstances(settingId)
+	if err != nil {
+		return nil, err
+	}
+	if len(bridges) == 0 {
+		return nil, errors.New("bridge token doesn't not exist")
+	}
+	b := &bridges[0]
+	var rets BridgeVolumeChartResponse
+	rets.SettingId = b.setting.Id
+	rets.BridgeName = b.setting.BridgeName
+	rets.ChainName = b.setting.ChainName
+
+	tz := time.Unix(GetNowTimeUnix(), 0)
+	today := time.Date(tz.Year(), tz.Month(), tz.Day(), 0, 0, 0, 0, tz.Location())
+	start := today.AddDate(0, 0, -1*(days-1))
+	for t := start; !t.After(today); t = t.AddDate(0, 0, 1) {
+		rets.Time = append(rets.Time, t.Unix())
+	}
+
+	for _, eco := range b.ecosystems() {
+		token := b.tokens[eco]
+		item := BridgeVolumeToken{
+			Ecosystem:   eco,
+			TokenSymbol: token.TokenSymbol,
+			Digits:      EcoDigits.GetInt64(eco, token.TokenDigits),
+		}
+		for _, tp := range []string{BridgeDeposit, BridgeWithdraw} {
+			var list []DaysAmount
+			args := bridgeTransferArgs(b)
+			args["ecosystem"] = eco
+			args["type"] = tp
+			args["start"] = start.UnixMilli()
+			err = GetDB(nil).Raw(`SELECT to_char(to_timestamp(created_at/1000),'yyyy-MM-dd') AS days,sum(amount) AS amount
+FROM `+bridgeTransferSQL+` AS v1 WHERE ecosystem = @ecosystem AND type = @type AND created_at >= @start GROUP BY days ORDER BY days ASC`, args).
+				Find(&list).Error
+			if err != nil {
+				log.WithFields(log.Fields{"error": err, "setting": settingId}).Error("Get Bridge Volume Chart Failed")
+				return nil, err
+			}
+			for _, t := range rets.Time {
+				amount := GetAmount(t, list).String()
+				if tp == BridgeDeposit {
+					item.Deposit = append(item.Deposit, amount)
+				} else {
+					item.Withdraw = append(item.Withdraw, amount)
+				}
+			}
+		}
+		rets.Tokens = append(rets.Tokens, item)
+	}
+	return &rets, nil
+}
@@ -0,0 +1,55 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBridgeApprovalRanges(t *testing.T) {
+	day := int64(24 * 60 * 60 * 1000)
+	withdrawals := []bridgeTransferRow{
+		{CreatedAt: 400 * day},
+		{CreatedAt: 10 * day},
+		{CreatedAt: 20 * day},
+	}
+	want := [][2]int64{
+		{10 * day, 20*day + bridgeApprovalWindow},
+		{400 * day, 400*day + bridgeApprovalWindow},
+	}
+	if got := bridgeApprovalRanges(withdrawals); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ranges = %v, want %v", got, want)
+	}
+}
+
+func TestBridgeTransferStatus(t *testing.T) {
+	b := &bridgeInstance{setting: BridgeSettings{DepositConfirm: 10, WithdrawConfirm: 5, Required: 2}, tokens: map[int64]BridgeToken{}}
+	tests := []struct {
+		name      string
+		row       bridgeTransferRow
+		maxBlock  int64
+		approvals int64
+		want      string
+	}{
+		{name: "deposit confirming", row: bridgeTransferRow{Type: BridgeDeposit, Block: 100}, maxBlock: 105, want: BridgeStatusConfirming},
+		{name: "deposit confirmed", row: bridgeTransferRow{Type: BridgeDeposit, Block: 100}, maxBlock: 110, want: BridgeStatusConfirmed},
+		{name: "withdraw confirming", row: bridgeTransferRow{Type: BridgeWithdraw, Block: 100}, maxBlock: 102, approvals: 2, want: BridgeStatusConfirming},
+		{name: "withdraw approving", row: bridgeTransferRow{Type: BridgeWithdraw, Block: 100}, maxBlock: 105, approvals: 1, want: BridgeStatusApproving},
+		{name: "withdraw approved", row: bridgeTransferRow{Type: BridgeWithdraw, Block: 100}, maxBlock: 105, approvals: 2, want: BridgeStatusApproved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := b.transfer(tt.row, tt.maxBlock, map[string]int64{"": tt.approvals})
+			if item.Status != tt.want {
+				t.Fatalf("status = %s, want %s", item.Status, tt.want)
+			}
+			if item.IbaxConfirmations != tt.maxBlock-tt.row.Block {
+				t.Fatalf("ibax confirmations = %d", item.IbaxConfirmations)
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	nodeSendTxPath   = "/api/v2/sendTx"
+	nodeTxStatusPath = "/api/v2/txstatus"
+
+	//unhealthyNodeTimeout how long a node is skipped after a failed broadcast
+	unhealthyNodeTimeout = time.Minute
+	//pendingTxTimeout pending tx that is not packed in this time will be dropped from tracker
+	pendingTxTimeout = time.Hour
+
+	ChannelTxStatus = "txStatus"
+)
+
+const (
+	PendingTxWaiting = iota
+	PendingTxSuccess
+	PendingTxFailed
+)
+
+type nodeSendTxResponse struct {
+	Hashes map[string]string `json:"hashes"`
+	Error  string            `json:"error"`
+	Msg    string            `json:"msg"`
+}
+
+type nodeTxStatusError struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+type nodeTxStatusResult struct {
+	BlockID string             `json:"blockid"`
+	Message string             `json:"result"`
+	Err     *nodeTxStatusError `json:"errmsg,omitempty"`
+	Penalty int64              `json:"penalty"`
+}
+
+type nodeTxStatusResponse struct {
+	Results map[string]nodeTxStatusResult `json:"results"`
+	Error   string                        `json:"error"`
+	Msg     string                        `json:"msg"`
+}
+
+type PendingTxStatus struct {
+	Hash       string `json:"hash"`
+	NodeName   string `json:"node_name"`
+	APIAddress string `json:"api_address"`
+	Status     int    `json:"status"` //0:pending 1:success 2:failed
+	BlockId    int64  `json:"block_id"`
+	Result     string `json:"result"`
+	Error      string `json:"error"`
+	Penalty    int64  `json:"penalty"`
+	SubmitTime int64  `json:"submit_time"`
+	UpdateTime int64  `json:"update_time"`
+}
+
+type BroadcastTxResponse struct {
+	Hash       string `json:"hash"`
+	NodeName   string `json:"node_name"`
+	APIAddress string `json:"api_address"`
+}
+
+type pendingTxTracker struct {
+	m map[string]*PendingTxStatus
+	sync.RWMutex
+}
+
+var (
+	PendingTxs     = &pendingTxTracker{m: make(map[string]*PendingTxStatus)}
+	unhealthyNodes sync.Map //key:api address value:time.Time
+)
+
+// Add starts tracking the hash, the status is resolved by PendingTxStatusSync
+func (p *pendingTxTracker) Add(hash string, node storage.HonorNodeModel) {
+	p.Lock()
+	defer p.Unlock()
+	now := time.Now().Unix()
+	p.m[hash] = &PendingTxStatus{
+		Hash:       hash,
+		NodeName:   node.NodeName,
+		APIAddress: node.APIAddress,
+		Status:     PendingTxWaiting,
+		SubmitTime: now,
+		UpdateTime: now,
+	}
+}
+
+func (p *pendingTxTracker) Get(hash string) (PendingTxStatus, bool) {
+	p.RLock()
+	defer p.RUnlock()
+	if v, ok := p.m[hash]; ok {
+		return *v, true
+	}
+	return PendingTxStatus{}, false
+}
+
+func (p *pendingTxTracker) waitingList() []PendingTxStatus {
+	p.RLock()
+	defer p.RUnlock()
+	var list []PendingTxStatus
+	for _, v := range p.m {
+		if v.Status == PendingTxWaiting {
+			list = append(list, *v)
+		}
+	}
+	return list
+}
+
+func (p *pendingTxTracker) update(rlt PendingTxStatus) {
+	p.Lock()
+	defer p.Unlock()
+	rlt.UpdateTime = time.Now().Unix()
+	p.m[rlt.Hash] = &rlt
+}
+
+// clean removes finished or expired entries
+func (p *pendingTxTracker) clean() {
+	p.Lock()
+	defer p.Unlock()
+	now := time.Now()
+	for k, v := range p.m {
+		if v.Status != PendingTxWaiting && now.Sub(time.Unix(v.UpdateTime, 0)) > pendingTxTimeout {
+			delete(p.m, k)
+		} else if now.Sub(time.Unix(v.SubmitTime, 0)) > pendingTxTimeout {
+			delete(p.m, k)
+		}
+	}
+}
+
+// getBroadcastNodes returns the honor nodes that can receive a tx, highest synchronized block first
+func getBroadcastNodes() []storage.HonorNodeModel {
+	var list []storage.HonorNodeModel
+	for _, v := range HonorNodes {
+		if v.APIAddress == "" {
+			continue
+		}
+		if t, ok := unhealthyNodes.Load(v.APIAddress); ok {
+			if time.Since(t.(time.Time)) < unhealthyNodeTimeout {
+				continue
+			}
+			unhealthyNodes.Delete(v.APIAddress)
+		}
+		list = append(list, v)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].NodeBlock > list[j].NodeBlock
+	})
+	return list
+}
+
+// nodeRejectError the node received the tx and rejected it, the node itself is healthy
+type nodeRejectError struct {
+	Err string
+	Msg string
+}
+
+func (e *nodeRejectError) Error() string {
+	return fmt.Sprintf("%s:%s", e.Err, e.Msg)
+}
+
+// sendTxToNode sends the tx to the node. The transport failures, the server errors and the unknown responses
+// are returned as the errors of the node, the errors in the node response are returned as nodeRejectError
+func sendTxToNode(apiAddress string, txHex string) (string, error) {
+	reqNew := make(url.Values)
+	reqNew["data"] = []string{txHex}
+
+	status, data, err := sendPostForm(apiAddress+nodeSendTxPath, reqNew)
+	if err != nil {
+		return "", err
+	}
+	if status >= http.StatusInternalServerError {
+		return "", fmt.Errorf("node response status %d", status)
+	}
+	var rets nodeSendTxResponse
+	if err := json.Unmarshal(data, &rets); err != nil {
+		log.WithFields(log.Fields{"error": err, "data": string(data)}).Error("sendTxToNode json err:")
+		return "", err
+	}
+	if rets.Error != "" {
+		return "", &nodeRejectError{Err: rets.Error, Msg: rets.Msg}
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("node response status %d", status)
+	}
+	hash, ok := rets.Hashes["data"]
+	if !ok || hash == "" {
+		return "", errors.New("node response hash is empty")
+	}
+	return hash, nil
+}
+
+// broadcastToNodes sends the tx to the nodes in order until one accepts it. A node that fails is skipped for
+// unhealthyNodeTimeout, a node that rejects the tx ends the broadcast with its error
+func broadcastToNodes(nodes []storage.HonorNodeModel, txHex string) (string, storage.HonorNodeModel, error) {
+	for _, node := range nodes {
+		hash, err := sendTxToNode(node.APIAddress, txHex)
+		if err != nil {
+			var reject *nodeRejectError
+			if errors.As(err, &reject) {
+				return "", node, fmt.Errorf("transaction rejected: %s", reject.Error())
+			}
+			log.WithFields(log.Fields{"error": err, "node": node.NodeName, "api address": node.APIAddress}).Warn("broadcast transaction failed")
+			unhealthyNodes.Store(node.APIAddress, time.Now())
+			continue
+		}
+		return hash, node, nil
+	}
+	return "", storage.HonorNodeModel{}, errors.New("broadcast transaction failed: all honor nodes are unavailable")
+}
+
+func getNodeTxStatus(apiAddress string, hashes []string) (map[string]nodeTxStatusResult, error) {
+	body, err := json.Marshal(map[string][]string{"hashes": hashes})
+	if err != nil {
+		return nil, err
+	}
+	reqNew := make(url.Values)
+	reqNew["data"] = []string{string(body)}
+
+	data, err := sendPostFormRequest(apiAddress+nodeTxStatusPath, reqNew)
+	if err != nil {
+		return nil, err
+	}
+	var rets nodeTxStatusResponse
+	if err := json.Unmarshal(data, &rets); err != nil {
+		log.WithFields(log.Fields{"error": err, "data": string(data)}).Error("getNodeTxStatus json err:")
+		return nil, err
+	}
+	if rets.Error != "" {
+		return nil, fmt.Errorf("%s:%s", rets.Error, rets.Msg)
+	}
+	return rets.Results, nil
+}
+
+// BroadcastTransaction decodes the signed tx, forwards it to a healthy honor node and registers the hash in the pending tracker
+func BroadcastTransaction(raw string) (*BroadcastTxResponse, error) {
+	txData, err := decodeRawTxData(raw)
+	if err != nil {
+		return nil, err
+	}
+	info, err := DecodeRawTransaction(hex.EncodeToString(txData))
+	if err != nil {
+		return nil, fmt.Errorf("decode transaction failed:%s", err.Error())
+	}
+
+	nodes := getBroadcastNodes()
+	if len(nodes) == 0 {
+		return nil, errors.New("no honor node available")
+	}
+	hash, node, err := broadcastToNodes(nodes, hex.EncodeToString(txData))
+	if err != nil {
+		return nil, err
+	}
+	if hash != info.Hash {
+		//the node hash is the one that the node reports the status of
+		log.WithFields(log.Fields{"node hash": hash, "hash": info.Hash}).Warn("broadcast transaction hash mismatch")
+	}
+	PendingTxs.Add(hash, node)
+	return &BroadcastTxResponse{
+		Hash:       hash,
+		NodeName:   node.NodeName,
+		APIAddress: node.APIAddress,
+	}, nil
+}
+
+// GetBroadcastTxStatus returns the tracked status, falling back to the indexed log transactions
+func GetBroadcastTxStatus(hash string) (*PendingTxStatus, error) {
+	hashHex, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, err
+	}
+	rlt, ok := PendingTxs.Get(hash)
+	if ok && rlt.Status != PendingTxWaiting {
+		return &rlt, nil
+	}
+	var lt LogTransaction
+	f, err := lt.GetByHash(hashHex)
+	if err != nil {
+		return nil, err
+	}
+	if f {
+		rlt.Hash = hash
+		rlt.BlockId = lt.Block
+		rlt.Status = PendingTxSuccess
+		if lt.Status != 0 {
+			rlt.Status = PendingTxFailed
+		}
+		rlt.UpdateTime = time.Now().Unix()
+		return &rlt, nil
+	}
+	if !ok {
+		return nil, errors.New("doesn't not hash")
+	}
+	return &rlt, nil
+}
+
+// PendingTxStatusSync polls the nodes for the tracked tx and pushes every resolved status to websocket
+func PendingTxStatusSync() {
+	list := PendingTxs.waitingList()
+	if len(list) == 0 {
+		PendingTxs.clean()
+		return
+	}
+	group := make(map[string][]PendingTxStatus)
+	for _, v := range list {
+		group[v.APIAddress] = append(group[v.APIAddress], v)
+	}
+	for apiAddress, txs := range group {
+		hashes := make([]string, 0, len(txs))
+		for _, v := range txs {
+			hashes = append(hashes, v.Hash)
+		}
+		results, err := getNodeTxStatus(apiAddress, hashes)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "api address": apiAddress}).Info("get node tx status failed")
+			continue
+		}
+		for _, v := range txs {
+			rlt, ok := results[v.Hash]
+			if !ok {
+				continue
+			}
+			if rlt.BlockID == "" && rlt.Err == nil {
+				continue
+			}
+			v.Result = rlt.Message
+			v.Penalty = rlt.Penalty
+			if rlt.Err != nil {
+				v.Status = PendingTxFailed
+				v.Error = rlt.Err.Error
+			} else {
+				v.Status = PendingTxSuccess
+			}
+			if rlt.BlockID != "" {
+				v.BlockId, _ = strconv.ParseInt(rlt.BlockID, 10, 64)
+			}
+			PendingTxs.update(v)
+			if err := SendDashboardDataToWebsocket(v, ChannelTxStatus); err != nil {
+				log.WithFields(log.Fields{"error": err, "hash": v.Hash}).Info("send tx status to websocket failed")
+			}
+		}
+	}
+	PendingTxs.clean()
+}
@@ -0,0 +1,215 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/storage"
+)
+
+// standInNode a local stand-in of the node api that answers sendTx with the status and the body
+type standInNode struct {
+	*httptest.Server
+	calls int32
+}
+
+func newStandInNode(t *testing.T, status int, body any) *standInNode {
+	node := &standInNode{}
+	node.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&node.calls, 1)
+		if r.URL.Path != nodeSendTxPath || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("data") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(node.Close)
+	return node
+}
+
+func resetUnhealthyNodes() {
+	unhealthyNodes.Range(func(key, value any) bool {
+		unhealthyNodes.Delete(key)
+		return true
+	})
+}
+
+func isUnhealthy(apiAddress string) bool {
+	_, ok := unhealthyNodes.Load(apiAddress)
+	return ok
+}
+
+func TestBroadcastToNodes(t *testing.T) {
+	accepted := map[string]any{"hashes": map[string]string{"data": "abcd"}}
+	rejected := map[string]any{"error": "E_LIMITFORSIGN", "msg": "invalid tx"}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name      string
+		nodes     func(t *testing.T) []*standInNode
+		extra     []string //api addresses that fail on transport
+		wantHash  string
+		wantNode  int //index of the accepting node in nodes
+		wantErr   string
+		unhealthy []int
+		healthy   []int
+		notCalled []int
+	}{
+		{
+			name: "first node accepts",
+			nodes: func(t *testing.T) []*standInNode {
+				return []*standInNode{newStandInNode(t, http.StatusOK, accepted), newStandInNode(t, http.StatusOK, accepted)}
+			},
+			wantHash:  "abcd",
+			healthy:   []int{0, 1},
+			notCalled: []int{1},
+		},
+		{
+			name: "server error falls back to the next node",
+			nodes: func(t *testing.T) []*standInNode {
+				return []*standInNode{newStandInNode(t, http.StatusInternalServerError, "down"), newStandInNode(t, http.StatusOK, accepted)}
+			},
+			wantHash:  "abcd",
+			wantNode:  1,
+			unhealthy: []int{0},
+			healthy:   []int{1},
+		},
+		{
+			name: "node reject is returned without marking the node",
+			nodes: func(t *testing.T) []*standInNode {
+				return []*standInNode{newStandInNode(t, http.StatusBadRequest, rejected), newStandInNode(t, http.StatusOK, accepted)}
+			},
+			wantErr:   "transaction rejected: E_LIMITFORSIGN:invalid tx",
+			healthy:   []int{0, 1},
+			notCalled: []int{1},
+		},
+		{
+			name: "unknown response falls back to the next node",
+			nodes: func(t *testing.T) []*standInNode {
+				return []*standInNode{newStandInNode(t, http.StatusOK, map[string]any{}), newStandInNode(t, http.StatusOK, accepted)}
+			},
+			wantHash:  "abcd",
+			wantNode:  1,
+			unhealthy: []int{0},
+		},
+		{
+			name: "all nodes unavailable",
+			nodes: func(t *testing.T) []*standInNode {
+				return []*standInNode{newStandInNode(t, http.StatusBadGateway, "down")}
+			},
+			extra:     []string{closed.URL},
+			wantErr:   "all honor nodes are unavailable",
+			unhealthy: []int{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetUnhealthyNodes()
+			defer resetUnhealthyNodes()
+			nodes := tt.nodes(t)
+			var list []storage.HonorNodeModel
+			for i, v := range nodes {
+				list = append(list, storage.HonorNodeModel{NodeName: "node" + string(rune('a'+i)), APIAddress: v.URL})
+			}
+			for _, v := range tt.extra {
+				list = append(list, storage.HonorNodeModel{NodeName: "extra", APIAddress: v})
+			}
+
+			hash, node, err := broadcastToNodes(list, "0102")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if hash != tt.wantHash || node.APIAddress != nodes[tt.wantNode].URL {
+					t.Fatalf("got %s from %s, want %s from %s", hash, node.APIAddress, tt.wantHash, nodes[tt.wantNode].URL)
+				}
+			}
+			for _, i := range tt.unhealthy {
+				if !isUnhealthy(nodes[i].URL) {
+					t.Errorf("node %d should be unhealthy", i)
+				}
+			}
+			for _, i := range tt.healthy {
+				if isUnhealthy(nodes[i].URL) {
+					t.Errorf("node %d should be healthy", i)
+				}
+			}
+			for _, i := range tt.notCalled {
+				if atomic.LoadInt32(&nodes[i].calls) != 0 {
+					t.Errorf("node %d should not be called", i)
+				}
+			}
+			for _, v := range tt.extra {
+				if !isUnhealthy(v) {
+					t.Errorf("%s should be unhealthy", v)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBroadcastNodes(t *testing.T) {
+	resetUnhealthyNodes()
+	defer resetUnhealthyNodes()
+	saved := HonorNodes
+	defer func() { HonorNodes = saved }()
+
+	HonorNodes = []storage.HonorNodeModel{
+		{NodeName: "low", APIAddress: "http://low", NodeBlock: 10},
+		{NodeName: "empty", NodeBlock: 30},
+		{NodeName: "high", APIAddress: "http://high", NodeBlock: 20},
+		{NodeName: "down", APIAddress: "http://down", NodeBlock: 40},
+	}
+	unhealthyNodes.Store("http://down", time.Now())
+
+	list := getBroadcastNodes()
+	var names []string
+	for _, v := range list {
+		names = append(names, v.NodeName)
+	}
+	if got := strings.Join(names, ","); got != "high,low" {
+		t.Fatalf("nodes = %s, want high,low", got)
+	}
+}
+
+func TestPendingTxTracker(t *testing.T) {
+	tracker := &pendingTxTracker{m: make(map[string]*PendingTxStatus)}
+	tracker.Add("abcd", storage.HonorNodeModel{NodeName: "node", APIAddress: "http://node"})
+
+	rlt, ok := tracker.Get("abcd")
+	if !ok || rlt.Status != PendingTxWaiting || rlt.APIAddress != "http://node" {
+		t.Fatalf("tracked = %+v %v", rlt, ok)
+	}
+	if list := tracker.waitingList(); len(list) != 1 {
+		t.Fatalf("waiting = %d, want 1", len(list))
+	}
+	rlt.Status = PendingTxSuccess
+	tracker.update(rlt)
+	if list := tracker.waitingList(); len(list) != 0 {
+		t.Fatalf("waiting = %d, want 0", len(list))
+	}
+	if _, ok := tracker.Get("none"); ok {
+		t.Fatal("unknown hash should not be tracked")
+	}
+}
@@ -0,0 +1,196 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	BurnSourceContract  = "contract"
+	BurnSourceToken     = "token"
+	BurnSourceUtxo      = "utxo"
+	BurnSourceBlackHole = "black_hole"
+
+	burnChartMaxDays = 365
+)
+
+// burnLedgerSQL the burned amounts of an ecosystem, it is the only definition of burned: the contract combustion(1_history type 16),
+// the token burns(1_history type 7, the burning_tokens of the ecosystem chart), the utxo fee combustion(spent_info_history type 6)
+// and the utxo transfers to the black hole address(spent_info_history type 2)
+const burnLedgerSQL = `(
+	SELECT txhash AS hash,block_id AS block,created_at,sender_id AS key_id,amount,CASE WHEN type = 16 THEN 'contract' ELSE 'token' END AS source
+		FROM "1_history" WHERE type IN(7,16) AND ecosystem = ?
+	UNION ALL
+	SELECT hash,block,created_at,sender_id AS key_id,amount,CASE WHEN type = 6 THEN 'utxo' ELSE 'black_hole' END AS source
+		FROM spent_info_history WHERE (type = 6 OR (type = 2 AND recipient_id = 0)) AND ecosystem = ?
+)`
+
+// burnLedger the burn ledger rows of the ecosystem
+func burnLedger(db *gorm.DB, ecosystem int64) *gorm.DB {
+	return db.Table(burnLedgerSQL+" AS v1", ecosystem, ecosystem)
+}
+
+func isUtxoBurn(source string) bool {
+	return source == BurnSourceUtxo || source == BurnSourceBlackHole
+}
+
+type BurnLedgerItem struct {
+	Hash         string          `json:"hash"`
+	Block        int64           `json:"block"`
+	CreatedAt    int64           `json:"created_at"`
+	Account      string          `json:"account"`
+	Amount       decimal.Decimal `json:"amount"`
+	Source       string          `json:"source"`
+	ContractName string          `json:"contract_name"`
+}
+
+type BurnContractRank struct {
+	ContractName string          `json:"contract_name"`
+	Tx           int64           `json:"tx"`
+	Amount       decimal.Decimal `json:"amount"`
+}
+
+type BurnSummaryResponse struct {
+	TokenSymbol  string             `json:"token_symbol"`
+	Digits       int                `json:"digits"`
+	TotalBurned  string             `json:"total_burned"`
+	TopContracts []BurnContractRank `json:"top_contracts"`
+}
+
+type BurnChartResponse struct {
+	TokenSymbol string   `json:"token_symbol"`
+	Name        string   `json:"name"` //ecosystem name
+	Digits      int      `json:"digits"`
+	Time        []int64  `json:"time"`
+	Amount      []string `json:"amount"`
+	Cumulative  []string `json:"cumulative"`
+}
+
+// GetTotalBurned the total burned amount of the ecosystem, the burned of the supply endpoints
+func GetTotalBurned(ecosystem int64) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	err := burnLedger(GetDB(nil), ecosystem).Select("COALESCE(sum(amount),0)").Take(&total).Error
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return total, nil
+}
+
+func GetBurnLedger(ecosystem int64, page, limit int) (*GeneralResponse, error) {
+	type ledger struct {
+		Hash         []byte
+		Block        int64
+		CreatedAt    int64
+		KeyId        int64
+		Amount       decimal.Decimal
+		Source       string
+		ContractName string
+	}
+	var (
+		rets GeneralResponse
+		list []ledger
+	)
+	rets.Page = page
+	rets.Limit = limit
+	err := burnLedger(GetDB(nil), ecosystem).Count(&rets.Total).Error
+	if err != nil {
+		return nil, err
+	}
+	err = burnLedger(GetDB(nil), ecosystem).
+		Select("v1.*,COALESCE(lt.contract_name,'') AS contract_name").
+		Joins("LEFT JOIN log_transactions AS lt ON(lt.hash = v1.hash)").
+		Order("block desc,created_at desc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]BurnLedgerItem, len(list))
+	for i, v := range list {
+		items[i] = BurnLedgerItem{
+			Hash:         hex.EncodeToString(v.Hash),
+			Block:        v.Block,
+			CreatedAt:    MsToSeconds(v.CreatedAt),
+			Account:      converter.AddressToString(v.KeyId),
+			Amount:       v.Amount,
+			Source:       v.Source,
+			ContractName: v.ContractName,
+		}
+		if isUtxoBurn(v.Source) && items[i].ContractName == "" {
+			items[i].ContractName = UtxoBurning
+		}
+	}
+	rets.List = items
+	return &rets, nil
+}
+
+func GetBurnSummary(ecosystem int64, limit int) (*BurnSummaryResponse, error) {
+	var rets BurnSummaryResponse
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(ecosystem)
+	total, err := GetTotalBurned(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	rets.TotalBurned = total.String()
+
+	err = burnLedger(GetDB(nil), ecosystem).
+		Select("CASE WHEN v1.source IN(?,?) THEN ? ELSE COALESCE(lt.contract_name,'') END AS contract_name,count(1) AS tx,sum(v1.amount) AS amount",
+			BurnSourceUtxo, BurnSourceBlackHole, UtxoBurning).
+		Joins("LEFT JOIN log_transactions AS lt ON(lt.hash = v1.hash)").
+		Group("1").Order("amount desc").Limit(limit).Find(&rets.TopContracts).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Burn Top Contracts Failed")
+		return nil, err
+	}
+	return &rets, nil
+}
+
+// GetBurnChart the daily burned amount and the cumulative burned amount of the last days
+func GetBurnChart(ecosystem int64, days int) (*BurnChartResponse, error) {
+	if days <= 0 || days > burnChartMaxDays {
+		return nil, errors.New("days invalid")
+	}
+	var (
+		rets BurnChartResponse
+		list []DaysAmount
+	)
+	rets.Name = EcoNames.Get(ecosystem)
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(ecosystem)
+
+	tz := time.Unix(GetNowTimeUnix(), 0)
+	today := time.Date(tz.Year(), tz.Month(), tz.Day(), 0, 0, 0, 0, tz.Location())
+	start := today.AddDate(0, 0, -1*(days-1))
+
+	var before decimal.Decimal
+	err := burnLedger(GetDB(nil), ecosystem).Select("COALESCE(sum(amount),0)").
+		Where("created_at < ?", start.UnixMilli()).Take(&before).Error
+	if err != nil {
+		return nil, err
+	}
+	err = burnLedger(GetDB(nil), ecosystem).
+		Select("to_char(to_timestamp(created_at/1000),'yyyy-MM-dd') AS days,sum(amount) AS amount").
+		Where("created_at >= ?", start.UnixMilli()).Group("days").Order("days asc").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	cumulative := before
+	for t := start; !t.After(today); t = t.AddDate(0, 0, 1) {
+		amount := GetAmount(t.Unix(), list)
+		cumulative = cumulative.Add(amount)
+		rets.Time = append(rets.Time, t.Unix())
+		rets.Amount = append(rets.Amount, amount.String())
+		rets.Cumulative = append(rets.Cumulative, cumulative.String())
+	}
+	return &rets, nil
+}
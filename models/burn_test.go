@@ -0,0 +1,54 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds the statements without a database connection
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestBurnLedger(t *testing.T) {
+	var total int64
+	stmt := burnLedger(dryRunDB(t), 5).Select("COALESCE(sum(amount),0)").Take(&total).Statement
+	sql := strings.Join(strings.Fields(stmt.SQL.String()), " ")
+	for _, want := range []string{
+		`FROM "1_history" WHERE type IN(7,16)`,
+		`CASE WHEN type = 16 THEN 'contract' ELSE 'token' END`,
+		`FROM spent_info_history WHERE (type = 6 OR (type = 2 AND recipient_id = 0))`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("burn ledger %q doesn't contain %q", sql, want)
+		}
+	}
+	if len(stmt.Vars) != 2 || stmt.Vars[0] != int64(5) || stmt.Vars[1] != int64(5) {
+		t.Errorf("burn ledger vars = %v, want the ecosystem of each part", stmt.Vars)
+	}
+}
+
+func TestIsUtxoBurn(t *testing.T) {
+	for source, want := range map[string]bool{
+		BurnSourceContract:  false,
+		BurnSourceToken:     false,
+		BurnSourceUtxo:      true,
+		BurnSourceBlackHole: true,
+	} {
+		if got := isUtxoBurn(source); got != want {
+			t.Errorf("isUtxoBurn(%s) = %v, want %v", source, got, want)
+		}
+	}
+}
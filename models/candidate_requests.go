@@ -400,6 +400,7 @@ LEFT JOIN(
 		rets.StakeRate = "0"
 	}
 	rets.Account = account
+	rets.Iname = GetIName(account)
 
 	return rets, nil
 }
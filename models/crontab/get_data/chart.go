@@ -41,5 +41,6 @@ func chartDataServer() {
 	go models.Get15DayBlockDiffChartDataToRedis()
 	go models.InsertDailyActiveReport()
 	go models.InsertDailyNodeReport()
+	go models.InsertHolderDistributionReport()
 	go models.DataChartHistoryServer()
 }
@@ -47,4 +47,6 @@ func historyDataServer() {
 	go models.SyncNationalFlagIcon()
 	go buffer.StartServer(buffer.GetBufferType(2))
 	go models.UpdatePairBuffer()
+	go models.SyncTokenPriceHistory()
+	go models.BlockIntegritySync()
 }
@@ -55,4 +55,5 @@ func realTimeDataServer() {
 	go models.GetAirdropLockAllTotal()
 	go models.GetMintNodeTotalBalance()
 	go models.UpdateAccountDetail()
+	go models.SyncParameterChange()
 }
@@ -203,6 +203,7 @@ ORDER BY gas_fee DESC
 		for i := 0; i < len(data); i++ {
 			rets.List[i].NodePosition = data[i].NodePosition
 			rets.List[i].KeyID = data[i].KeyID
+			rets.List[i].Iname = GetIName(data[i].KeyID)
 			rets.List[i].NodeName = data[i].NodeName
 			rets.List[i].Country = data[i].Country
 			rets.List[i].IconUrl = data[i].IconUrl
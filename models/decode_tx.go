@@ -0,0 +1,133 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+)
+
+type DecodeTxHeader struct {
+	ID          int    `json:"id"`
+	Ecosystem   int64  `json:"ecosystem"`
+	KeyId       string `json:"key_id"`
+	Time        int64  `json:"time"`
+	NetworkId   int64  `json:"network_id"`
+	PublicKey   string `json:"public_key"`
+	TokenSymbol string `json:"token_symbol"`
+}
+
+type DecodeTxResponse struct {
+	Hash         string         `json:"hash"`
+	Type         int64          `json:"type"`
+	Size         int64          `json:"size"`
+	IsUtxo       bool           `json:"is_utxo"`
+	Header       DecodeTxHeader `json:"header"`
+	ContractName string         `json:"contract_name"`
+	Params       string         `json:"params"`
+	Signature    string         `json:"signature"`
+	SignedBy     string         `json:"signed_by,omitempty"`
+	Expedite     string         `json:"expedite"`
+	MaxSum       string         `json:"max_sum"`
+	PayOver      string         `json:"pay_over"`
+	Lang         string         `json:"lang,omitempty"`
+}
+
+// decodeRawTxData accepts hex (with or without 0x) or standard/url base64
+func decodeRawTxData(raw string) ([]byte, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("tx data is empty")
+	}
+	hexStr := strings.TrimPrefix(strings.TrimPrefix(raw, "0x"), "0X")
+	if data, err := hex.DecodeString(hexStr); err == nil {
+		return data, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(raw); err == nil {
+			return data, nil
+		}
+	}
+	return nil, errors.New("tx data is neither hex nor base64 encoded")
+}
+
+func DecodeRawTransaction(raw string) (*DecodeTxResponse, error) {
+	txData, err := decodeRawTxData(raw)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := UnmarshallTransaction(bytes.NewBuffer(txData))
+	if err != nil {
+		return nil, err
+	}
+	if !tx.IsSmartContract() {
+		return nil, errors.New("doesn't not Smart Contract transaction")
+	}
+	isUtxo, err := IsUtxoTransaction(txData, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := tx.SmartContract()
+	rets := &DecodeTxResponse{
+		Hash:      hex.EncodeToString(tx.Hash()),
+		Type:      int64(tx.Type()),
+		Size:      int64(len(txData)),
+		IsUtxo:    isUtxo,
+		Signature: hex.EncodeToString(sc.TxSignature),
+		Expedite:  sc.TxSmart.Expedite,
+		MaxSum:    sc.TxSmart.MaxSum,
+		PayOver:   sc.TxSmart.PayOver,
+		Lang:      sc.TxSmart.Lang,
+	}
+	if sc.TxSmart.SignedBy != 0 {
+		rets.SignedBy = converter.AddressToString(sc.TxSmart.SignedBy)
+	}
+	if sc.TxSmart.Header != nil {
+		rets.Header = DecodeTxHeader{
+			ID:        sc.TxSmart.Header.ID,
+			Ecosystem: sc.TxSmart.Header.EcosystemID,
+			KeyId:     converter.AddressToString(sc.TxSmart.Header.KeyID),
+			Time:      MsToSeconds(sc.TxSmart.Header.Time),
+			NetworkId: sc.TxSmart.Header.NetworkID,
+			PublicKey: hex.EncodeToString(sc.TxSmart.Header.PublicKey),
+		}
+	}
+	if rets.Header.Ecosystem == 0 {
+		rets.Header.Ecosystem = 1
+	}
+	rets.Header.TokenSymbol = Tokens.Get(rets.Header.Ecosystem)
+	if rets.Header.Ecosystem == 1 {
+		rets.Header.TokenSymbol = SysTokenSymbol
+	}
+
+	if sc.TxSmart.UTXO != nil {
+		rets.ContractName = UtxoTx
+		dataBytes, _ := json.Marshal(sc.TxSmart.UTXO)
+		rets.Params = string(dataBytes)
+		if converter.AddressToString(sc.TxSmart.UTXO.ToID) == BlackHoleAddr {
+			rets.ContractName = UtxoBurning
+		}
+	} else if sc.TxSmart.TransferSelf != nil {
+		rets.ContractName = UtxoTransferSelf
+		dataBytes, _ := json.Marshal(sc.TxSmart.TransferSelf)
+		rets.Params = string(dataBytes)
+	} else {
+		var name string
+		if sc.TxContract != nil {
+			name = sc.TxContract.Name
+		}
+		rets.ContractName, rets.Params = GetMineParam(rets.Header.Ecosystem, name, sc.TxData, tx.Hash())
+	}
+
+	return rets, nil
+}
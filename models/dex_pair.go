@@ -0,0 +1,329 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	dexSwapFeeName   = "swap_fee"
+	dexDefaultFee    = "0.003"
+	dexEventsLimit   = 20
+	dexPairListLimit = 50
+)
+
+type DexPairVolume struct {
+	Swaps              int64  `json:"swaps"`
+	Volume1            string `json:"volume1"`              //the token1 paid into the pair by swaps
+	Volume2            string `json:"volume2"`              //the token2 paid into the pair by swaps
+	Volume             string `json:"volume"`               //valued in the rank ecosystem token
+	EstimatedFeeIncome string `json:"estimated_fee_income"` //the volume by the fee rate, the swaps don't record the fee
+	Adds               int64  `json:"adds"`
+	Removes            int64  `json:"removes"`
+}
+
+type DexPairInfo struct {
+	Id           int64           `json:"id"`
+	PairAccount  string          `json:"pair_account"`
+	Ecosystem1   int64           `json:"ecosystem1"`
+	Ecosystem2   int64           `json:"ecosystem2"`
+	TokenSymbol1 string          `json:"token_symbol1"`
+	TokenSymbol2 string          `json:"token_symbol2"`
+	Digits1      int             `json:"digits1"`
+	Digits2      int             `json:"digits2"`
+	Reserve1     decimal.Decimal `json:"reserve1"`
+	Reserve2     decimal.Decimal `json:"reserve2"`
+	Liquidity    decimal.Decimal `json:"liquidity"`
+	Status       string          `json:"status"`
+	FeeRate      string          `json:"fee_rate"`
+	FeeDefault   bool            `json:"fee_default"` //the swap_fee param doesn't exist, the default rate is used
+	Tvl          string          `json:"tvl"`         //valued in the rank ecosystem token
+	Volume24h    DexPairVolume   `json:"volume_24h"`
+	Volume7d     DexPairVolume   `json:"volume_7d"`
+}
+
+type DexLiquidityEvent struct {
+	Hash      string          `json:"hash"`
+	Type      string          `json:"type"` //add or remove
+	Account   string          `json:"account"`
+	Amount1   decimal.Decimal `json:"amount1"`
+	Amount2   decimal.Decimal `json:"amount2"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+type DexPairDetail struct {
+	DexPairInfo
+	LiquidityEvents []DexLiquidityEvent `json:"liquidity_events"`
+}
+
+type DexTvlResponse struct {
+	RankEcosystem int64  `json:"rank_ecosystem"`
+	TokenSymbol   string `json:"token_symbol"`
+	Pairs         int64  `json:"pairs"`
+	Tvl           string `json:"tvl"`
+}
+
+// dexPairTxSQL the token flows of a pair account grouped by tx, the fee rows are excluded
+const dexPairTxSQL = `(
+	SELECT txhash,max(created_at) AS created_at,
+		max(CASE WHEN recipient_id = @pair THEN sender_id ELSE recipient_id END) AS key_id,
+		sum(CASE WHEN ecosystem = @eco1 AND recipient_id = @pair THEN amount ELSE 0 END) AS in1,
+		sum(CASE WHEN ecosystem = @eco2 AND recipient_id = @pair THEN amount ELSE 0 END) AS in2,
+		sum(CASE WHEN ecosystem = @eco1 AND sender_id = @pair THEN amount ELSE 0 END) AS out1,
+		sum(CASE WHEN ecosystem = @eco2 AND sender_id = @pair THEN amount ELSE 0 END) AS out2
+	FROM "1_history" WHERE (recipient_id = @pair OR sender_id = @pair) AND ecosystem IN(@eco1,@eco2)
+		AND type NOT IN(1,2) AND created_at >= @start
+	GROUP BY txhash
+)`
+
+func dexEnable() error {
+	if !conf.GetEnvConf().Defi.Enable {
+		return errors.New("defi not enable")
+	}
+	return nil
+}
+
+type dexSwapFee struct {
+	rate       decimal.Decimal
+	useDefault bool
+}
+
+// getDexSwapFee the swap_fee param of the defi ecosystem, the default rate when the param doesn't exist
+func getDexSwapFee() dexSwapFee {
+	p := &Param{}
+	f, err := p.Get(dexSwapFeeName)
+	if err == nil && f {
+		if rate, err := decimal.NewFromString(p.Value); err == nil {
+			return dexSwapFee{rate: rate}
+		}
+	}
+	rate, _ := decimal.NewFromString(dexDefaultFee)
+	return dexSwapFee{rate: rate, useDefault: true}
+}
+
+// dexPairQuery the pairs of the pair table, the list and the tvl read the same rows
+func dexPairQuery() *gorm.DB {
+	var pair Pair
+	return GetDB(nil).Table(pair.TableName())
+}
+
+// dexTokenValue the raw token amount of the ecosystem valued in rank ecosystem token units
+func dexTokenValue(ecosystem int64, amount decimal.Decimal, prices map[int64]string) decimal.Decimal {
+	price, _ := decimal.NewFromString(prices[ecosystem])
+	return amount.Shift(int32(-EcoDigits.GetInt(ecosystem, 0))).Mul(price)
+}
+
+func getDexPairVolume(pair *Pair, start int64, prices map[int64]string, fee dexSwapFee) (DexPairVolume, error) {
+	type volume struct {
+		Swaps   int64
+		In1     decimal.Decimal
+		In2     decimal.Decimal
+		Adds    int64
+		Removes int64
+	}
+	var (
+		rets DexPairVolume
+		v    volume
+	)
+	args := map[string]any{
+		"pair": converter.StringToAddress(pair.PairAccount), "eco1": pair.Ecosystem1, "eco2": pair.Ecosystem2, "start": start * 1000,
+	}
+	err := GetDB(nil).Raw(`
+SELECT count(1) FILTER(WHERE (in1 > 0 AND out2 > 0) OR (in2 > 0 AND out1 > 0)) AS swaps,
+	COALESCE(sum(in1) FILTER(WHERE in1 > 0 AND out2 > 0),0) AS in1,
+	COALESCE(sum(in2) FILTER(WHERE in2 > 0 AND out1 > 0),0) AS in2,
+	count(1) FILTER(WHERE in1 > 0 AND in2 > 0) AS adds,
+	count(1) FILTER(WHERE out1 > 0 AND out2 > 0) AS removes
+FROM `+dexPairTxSQL+` AS v1`, args).Take(&v).Error
+	if err != nil {
+		return rets, err
+	}
+	value := dexTokenValue(pair.Ecosystem1, v.In1, prices).Add(dexTokenValue(pair.Ecosystem2, v.In2, prices))
+	rets.Swaps = v.Swaps
+	rets.Volume1 = v.In1.String()
+	rets.Volume2 = v.In2.String()
+	rets.Volume = value.String()
+	rets.EstimatedFeeIncome = value.Mul(fee.rate).String()
+	rets.Adds = v.Adds
+	rets.Removes = v.Removes
+	return rets, nil
+}
+
+func getDexPairInfo(pair *Pair, prices map[int64]string, fee dexSwapFee) (DexPairInfo, error) {
+	var (
+		rets DexPairInfo
+		err  error
+	)
+	rets.Id = pair.Id
+	rets.PairAccount = pair.PairAccount
+	rets.Ecosystem1 = pair.Ecosystem1
+	rets.Ecosystem2 = pair.Ecosystem2
+	rets.TokenSymbol1, rets.Digits1 = holderTokenInfo(pair.Ecosystem1)
+	rets.TokenSymbol2, rets.Digits2 = holderTokenInfo(pair.Ecosystem2)
+	rets.Reserve1 = pair.Reserve1
+	rets.Reserve2 = pair.Reserve2
+	rets.Liquidity = pair.Liquidity
+	rets.Status = pair.Status
+	rets.FeeRate = fee.rate.String()
+	rets.FeeDefault = fee.useDefault
+	rets.Tvl = dexTokenValue(pair.Ecosystem1, pair.Reserve1, prices).Add(dexTokenValue(pair.Ecosystem2, pair.Reserve2, prices)).String()
+
+	now := GetNowTimeUnix()
+	rets.Volume24h, err = getDexPairVolume(pair, now-86400, prices, fee)
+	if err != nil {
+		return rets, err
+	}
+	rets.Volume7d, err = getDexPairVolume(pair, now-7*86400, prices, fee)
+	if err != nil {
+		return rets, err
+	}
+	return rets, nil
+}
+
+func getDexPrices(pairs []Pair) (map[int64]string, error) {
+	var ecosystems []int64
+	for _, v := range pairs {
+		ecosystems = append(ecosystems, v.Ecosystem1, v.Ecosystem2)
+	}
+	return GetTokenPrices(ecosystems)
+}
+
+func GetDexPairList(page, limit int) (*GeneralResponse, error) {
+	if err := dexEnable(); err != nil {
+		return nil, err
+	}
+	if limit > dexPairListLimit {
+		limit = dexPairListLimit
+	}
+	var (
+		rets  GeneralResponse
+		pairs []Pair
+	)
+	rets.Page = page
+	rets.Limit = limit
+	if err := dexPairQuery().Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	err := dexPairQuery().Order("liquidity desc,id asc").Offset((page - 1) * limit).Limit(limit).Find(&pairs).Error
+	if err != nil {
+		return nil, err
+	}
+	prices, err := getDexPrices(pairs)
+	if err != nil {
+		return nil, err
+	}
+	fee := getDexSwapFee()
+	list := make([]DexPairInfo, len(pairs))
+	for i := range pairs {
+		list[i], err = getDexPairInfo(&pairs[i], prices, fee)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "pair": pairs[i].Id}).Error("Get Dex Pair Info Failed")
+			return nil, err
+		}
+	}
+	rets.List = list
+	return &rets, nil
+}
+
+func GetDexPairDetail(id int64) (*DexPairDetail, error) {
+	if err := dexEnable(); err != nil {
+		return nil, err
+	}
+	var (
+		rets DexPairDetail
+		pair Pair
+	)
+	f, err := isFound(dexPairQuery().Where("id = ?", id).Take(&pair))
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("pair doesn't not exist")
+	}
+	prices, err := getDexPrices([]Pair{pair})
+	if err != nil {
+		return nil, err
+	}
+	rets.DexPairInfo, err = getDexPairInfo(&pair, prices, getDexSwapFee())
+	if err != nil {
+		return nil, err
+	}
+
+	type event struct {
+		Txhash    []byte
+		CreatedAt int64
+		KeyId     int64
+		In1       decimal.Decimal
+		In2       decimal.Decimal
+		Out1      decimal.Decimal
+		Out2      decimal.Decimal
+	}
+	var events []event
+	args := map[string]any{
+		"pair": converter.StringToAddress(pair.PairAccount), "eco1": pair.Ecosystem1, "eco2": pair.Ecosystem2, "start": 0,
+		"limit": dexEventsLimit,
+	}
+	err = GetDB(nil).Raw(`SELECT * FROM `+dexPairTxSQL+` AS v1 WHERE (in1 > 0 AND in2 > 0) OR (out1 > 0 AND out2 > 0)
+ORDER BY created_at DESC LIMIT @limit`, args).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	rets.LiquidityEvents = make([]DexLiquidityEvent, len(events))
+	for i, v := range events {
+		ev := DexLiquidityEvent{
+			Hash:      hex.EncodeToString(v.Txhash),
+			Account:   converter.AddressToString(v.KeyId),
+			CreatedAt: MsToSeconds(v.CreatedAt),
+		}
+		if v.In1.GreaterThan(decimal.Zero) && v.In2.GreaterThan(decimal.Zero) {
+			ev.Type, ev.Amount1, ev.Amount2 = "add", v.In1, v.In2
+		} else {
+			ev.Type, ev.Amount1, ev.Amount2 = "remove", v.Out1, v.Out2
+		}
+		rets.LiquidityEvents[i] = ev
+	}
+	return &rets, nil
+}
+
+// GetDexTvl the total value locked of the running pairs, valued in the rank ecosystem token
+func GetDexTvl() (*DexTvlResponse, error) {
+	if err := dexEnable(); err != nil {
+		return nil, err
+	}
+	var rets DexTvlResponse
+	rets.RankEcosystem = AllowRankEcosystem
+	rets.TokenSymbol, _ = holderTokenInfo(AllowRankEcosystem)
+
+	var pairs []PairEcosystem
+	err := dexPairQuery().Select("id,ecosystem1,ecosystem2,reserve1,reserve2,liquidity").Where("status = 'run'").Find(&pairs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var ecosystems []int64
+	for _, v := range pairs {
+		ecosystems = append(ecosystems, v.Ecosystem1, v.Ecosystem2)
+	}
+	prices, err := GetTokenPrices(ecosystems)
+	if err != nil {
+		return nil, err
+	}
+	tvl := decimal.Zero
+	for _, v := range pairs {
+		tvl = tvl.Add(dexTokenValue(v.Ecosystem1, v.Reserve1, prices)).Add(dexTokenValue(v.Ecosystem2, v.Reserve2, prices))
+	}
+	rets.Pairs = int64(len(pairs))
+	rets.Tvl = tvl.String()
+	return &rets, nil
+}
@@ -0,0 +1,549 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	CompareHolders     = "holders"
+	CompareActive7d    = "active_accounts_7d"
+	CompareActive30d   = "active_accounts_30d"
+	CompareTxCount     = "tx_count"
+	CompareFees        = "fees"
+	CompareTotalSupply = "total_supply"
+	CompareCirculating = "circulating_supply"
+	CompareBurned      = "burned"
+	CompareApps        = "apps"
+	CompareContracts   = "contracts"
+	CompareTables      = "tables"
+	ComparePrice       = "price"
+	CompareLiquidity   = "liquidity"
+
+	CompareMinEcosystems = 2
+	CompareMaxEcosystems = 10
+	CompareDefaultDays   = 30
+	compareMaxDays       = 365
+	compareCacheTime     = 10 * 60
+)
+
+// compareCacheItem the metrics of an ecosystem and days, the lock of the item is held while they are computed
+// so the same metrics are computed once, today and time are written with both locks held
+type compareCacheItem struct {
+	sync.Mutex
+	today   int64
+	time    int64
+	metrics map[string]compareMetric
+}
+
+var (
+	//compareLock guards the cache map only, the metrics are computed out of it
+	compareLock  sync.Mutex
+	compareCache = make(map[string]*compareCacheItem)
+)
+
+// compareMetrics the metrics in the order of the response
+var compareMetrics = []string{
+	CompareHolders, CompareActive7d, CompareActive30d, CompareTxCount, CompareFees, CompareTotalSupply,
+	CompareCirculating, CompareBurned, CompareApps, CompareContracts, CompareTables, ComparePrice, CompareLiquidity,
+}
+
+// compareActiveSQL the accounts that sent or received the ecosystem token in the window days up to each day
+const compareActiveSQL = `
+WITH act AS(
+	SELECT DISTINCT to_timestamp(created_at/1000)::date AS day,key_id FROM(
+		SELECT created_at,sender_id AS key_id FROM "1_history" WHERE ecosystem = @eco AND sender_id <> 0 AND created_at >= @start
+		UNION ALL
+		SELECT created_at,recipient_id AS key_id FROM "1_history" WHERE ecosystem = @eco AND recipient_id <> 0 AND created_at >= @start
+		UNION ALL
+		SELECT created_at,sender_id AS key_id FROM spent_info_history WHERE ecosystem = @eco AND sender_id <> 0 AND created_at >= @start
+		UNION ALL
+		SELECT created_at,recipient_id AS key_id FROM spent_info_history WHERE ecosystem = @eco AND recipient_id <> 0 AND created_at >= @start
+	)AS v1
+)
+SELECT to_char(d.day,'yyyy-MM-dd') AS days,count(DISTINCT act.key_id) AS num
+FROM generate_series(CAST(@first AS date),CAST(@last AS date),'1 day') AS d(day)
+LEFT JOIN act ON(act.day > d.day::date - @window AND act.day <= d.day::date)
+GROUP BY d.day
+`
+
+type EcosystemCompareInfo struct {
+	Ecosystem   int64  `json:"ecosystem"`
+	Name        string `json:"name"`
+	TokenSymbol string `json:"token_symbol"`
+	Digits      int    `json:"digits"`
+}
+
+// EcosystemCompareMetric a metric of the compared ecosystems, values and series are in the order of the ecosystems
+// and each series is aligned with the time of the response
+type EcosystemCompareMetric struct {
+	Name   string     `json:"name"`
+	Values []string   `json:"values"`
+	Series [][]string `json:"series"`
+}
+
+// EcosystemCompareResponse
+// the token amounts are in token units, price and liquidity are valued in the base token(AllowRankEcosystem)
+type EcosystemCompareResponse struct {
+	BaseEcosystem   int64                    `json:"base_ecosystem"`
+	BaseTokenSymbol string                   `json:"base_token_symbol"`
+	Time            []int64                  `json:"time"`
+	Ecosystems      []EcosystemCompareInfo   `json:"ecosystems"`
+	Metrics         []EcosystemCompareMetric `json:"metrics"`
+}
+
+type compareMetric struct {
+	value  string
+	series []string
+}
+
+type compareContext struct {
+	ecosystem int64
+	digits    int
+	days      []time.Time
+	metrics   map[string]compareMetric
+}
+
+func (c *compareContext) start() time.Time {
+	return c.days[0]
+}
+
+// end the start of the day after the last day
+func (c *compareContext) end() time.Time {
+	return c.days[len(c.days)-1].AddDate(0, 0, 1)
+}
+
+func (c *compareContext) unit(d decimal.Decimal) string {
+	return d.Shift(int32(-c.digits)).String()
+}
+
+func (c *compareContext) compute() error {
+	for _, fn := range []func(*compareContext) error{
+		compareHolders, compareActive, compareTxCount, compareFees, compareSupply, compareObjects, comparePrice, compareLiquidity,
+	} {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getCompareMetrics the cached metrics of the context ecosystem and days, they are computed on a cache miss
+func getCompareMetrics(c *compareContext, today int64) (map[string]compareMetric, error) {
+	key := fmt.Sprintf("%d-%d", c.ecosystem, len(c.days))
+	now := time.Now().Unix()
+	compareLock.Lock()
+	for k, v := range compareCache {
+		if v.time > 0 && (v.today != today || now-v.time >= compareCacheTime) {
+			delete(compareCache, k)
+		}
+	}
+	item, ok := compareCache[key]
+	if !ok {
+		item = &compareCacheItem{}
+		compareCache[key] = item
+	}
+	compareLock.Unlock()
+
+	item.Lock()
+	defer item.Unlock()
+	if item.metrics != nil && item.today == today && now-item.time < compareCacheTime {
+		return item.metrics, nil
+	}
+	if err := c.compute(); err != nil {
+		return nil, err
+	}
+	compareLock.Lock()
+	item.today, item.time, item.metrics = today, time.Now().Unix(), c.metrics
+	compareLock.Unlock()
+	return item.metrics, nil
+}
+
+// GetEcosystemCompare the metrics of the ecosystems side by side with the daily series of the last days
+func GetEcosystemCompare(ecosystems []int64, days int) (*EcosystemCompareResponse, error) {
+	if len(ecosystems) < CompareMinEcosystems || len(ecosystems) > CompareMaxEcosystems {
+		return nil, fmt.Errorf("ecosystems must be %d-%d", CompareMinEcosystems, CompareMaxEcosystems)
+	}
+	if days <= 0 || days > compareMaxDays {
+		return nil, errors.New("days invalid")
+	}
+	exist := make(map[int64]bool)
+	for _, v := range ecosystems {
+		if exist[v] {
+			return nil, errors.New("request params invalid")
+		}
+		exist[v] = true
+		if Info.Get(v).Id != v {
+			return nil, fmt.Errorf("ecosystem %d doesn't not exist", v)
+		}
+	}
+
+	var rets EcosystemCompareResponse
+	rets.BaseEcosystem = AllowRankEcosystem
+	rets.BaseTokenSymbol, _ = holderTokenInfo(AllowRankEcosystem)
+
+	tz := time.Unix(GetNowTimeUnix(), 0)
+	today := time.Date(tz.Year(), tz.Month(), tz.Day(), 0, 0, 0, 0, tz.Location())
+	var dayList []time.Time
+	for t := today.AddDate(0, 0, -1*(days-1)); !t.After(today); t = t.AddDate(0, 0, 1) {
+		dayList = append(dayList, t)
+		rets.Time = append(rets.Time, t.Unix())
+	}
+
+	list := make([]map[string]compareMetric, len(ecosystems))
+	for i, eco := range ecosystems {
+		info := EcosystemCompareInfo{Ecosystem: eco, Name: EcoNames.Get(eco)}
+		info.TokenSymbol, info.Digits = holderTokenInfo(eco)
+		rets.Ecosystems = append(rets.Ecosystems, info)
+
+		ctx := &compareContext{ecosystem: eco, digits: info.Digits, days: dayList, metrics: make(map[string]compareMetric)}
+		metrics, err := getCompareMetrics(ctx, today.Unix())
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "ecosystem": eco}).Error("Get Ecosystem Compare Failed")
+			return nil, err
+		}
+		list[i] = metrics
+	}
+
+	for _, name := range compareMetrics {
+		m := EcosystemCompareMetric{Name: name}
+		for _, v := range list {
+			m.Values = append(m.Values, v[name].value)
+			m.Series = append(m.Series, v[name].series)
+		}
+		rets.Metrics = append(rets.Metrics, m)
+	}
+	return &rets, nil
+}
+
+// compareHolders the current holders, the series are the daily holder distribution reports
+func compareHolders(c *compareContext) error {
+	var (
+		rets  compareMetric
+		total int64
+		list  []HolderDistributionReport
+		first int64
+	)
+	if err := holderQuery(c.ecosystem).Count(&total).Error; err != nil {
+		return err
+	}
+	rets.value = strconv.FormatInt(total, 10)
+
+	err := GetDB(nil).Model(&HolderDistributionReport{}).Select("COALESCE(max(time),0)").
+		Where("ecosystem = ? AND time <= ?", c.ecosystem, c.start().Unix()).Take(&first).Error
+	if err != nil {
+		return err
+	}
+	err = GetDB(nil).Select("time,holders").Where("ecosystem = ? AND time >= ? AND time < ?", c.ecosystem, first, c.end().Unix()).
+		Order("time asc").Find(&list).Error
+	if err != nil {
+		return err
+	}
+	var (
+		holders int64
+		i       int
+	)
+	for _, d := range c.days {
+		for ; i < len(list) && list[i].Time <= d.Unix(); i++ {
+			holders = list[i].Holders
+		}
+		rets.series = append(rets.series, strconv.FormatInt(holders, 10))
+	}
+	c.metrics[CompareHolders] = rets
+	return nil
+}
+
+// compareActive the active accounts of the last 7 and 30 days, the series are the rolling windows of each day
+func compareActive(c *compareContext) error {
+	for name, window := range map[string]int{CompareActive7d: 7, CompareActive30d: 30} {
+		var (
+			rets compareMetric
+			list []DaysNumber
+		)
+		err := GetDB(nil).Raw(compareActiveSQL, map[string]any{
+			"eco":    c.ecosystem,
+			"start":  c.start().AddDate(0, 0, -1*(window-1)).UnixMilli(),
+			"first":  c.start().Format("2006-01-02"),
+			"last":   c.days[len(c.days)-1].Format("2006-01-02"),
+			"window": window,
+		}).Find(&list).Error
+		if err != nil {
+			return err
+		}
+		for _, d := range c.days {
+			rets.series = append(rets.series, strconv.FormatInt(GetDaysNumber(d.Unix(), list), 10))
+		}
+		rets.value = rets.series[len(rets.series)-1]
+		c.metrics[name] = rets
+	}
+	return nil
+}
+
+func compareTxCount(c *compareContext) error {
+	var (
+		rets compareMetric
+		list []DaysNumber
+	)
+	err := GetDB(nil).Raw(`SELECT to_char(to_timestamp("timestamp"/1000),'yyyy-MM-dd') AS days,count(1) AS num
+FROM log_transactions WHERE ecosystem_id = ? AND "timestamp" >= ? GROUP BY days`, c.ecosystem, c.start().UnixMilli()).Find(&list).Error
+	if err != nil {
+		return err
+	}
+	rets.value = strconv.FormatInt(EcoTxCount.GetInt64(c.ecosystem, 0), 10)
+	for _, d := range c.days {
+		rets.series = append(rets.series, strconv.FormatInt(GetDaysNumber(d.Unix(), list), 10))
+	}
+	c.metrics[CompareTxCount] = rets
+	return nil
+}
+
+// compareFees the total fees paid in the ecosystem token, the series are the daily fees
+func compareFees(c *compareContext) error {
+	var (
+		rets  compareMetric
+		total decimal.Decimal
+		list  []DaysAmount
+	)
+	err := GetDB(nil).Table(feeFlowSQL+" AS v1", c.ecosystem, c.ecosystem).Select("COALESCE(sum(amount),0)").Take(&total).Error
+	if err != nil {
+		return err
+	}
+	err = GetDB(nil).Table(feeFlowSQL+" AS v1", c.ecosystem, c.ecosystem).
+		Select("to_char(to_timestamp(created_at/1000),'yyyy-MM-dd') AS days,sum(amount) AS amount").
+		Where("created_at >= ?", c.start().UnixMilli()).Group("days").Find(&list).Error
+	if err != nil {
+		return err
+	}
+	rets.value = c.unit(total)
+	for _, d := range c.days {
+		rets.series = append(rets.series, c.unit(GetAmount(d.Unix(), list)))
+	}
+	c.metrics[CompareFees] = rets
+	return nil
+}
+
+// compareSupply the supply and the burned amount. The supply series go back from the current supply by the issued
+// (1_history type 6,29) and burned amounts of the later days, the mining rewards of the platform token are not included
+func compareSupply(c *compareContext) error {
+	supply, err := GetSupply(c.ecosystem)
+	if err != nil {
+		return err
+	}
+	burn, err := GetBurnChart(c.ecosystem, len(c.days))
+	if err != nil {
+		return err
+	}
+	total, err := GetTotalBurned(c.ecosystem)
+	if err != nil {
+		return err
+	}
+	var issued []DaysAmount
+	err = GetDB(nil).Table(`"1_history"`).Select("to_char(to_timestamp(created_at/1000),'yyyy-MM-dd') AS days,sum(amount) AS amount").
+		Where("ecosystem = ? AND type IN(6,29) AND created_at >= ?", c.ecosystem, c.start().UnixMilli()).Group("days").Find(&issued).Error
+	if err != nil {
+		return err
+	}
+
+	totalSupply, _ := decimal.NewFromString(supply.TotalSupply)
+	circulating, _ := decimal.NewFromString(supply.CirculatingSupply)
+	n := len(c.days)
+	var (
+		rTotal  = compareMetric{value: supply.TotalSupply, series: make([]string, n)}
+		rCir    = compareMetric{value: supply.CirculatingSupply, series: make([]string, n)}
+		rBurned = compareMetric{value: c.unit(total), series: make([]string, n)}
+		change  = decimal.Zero //the net supply change of the later days, in token units
+	)
+	for i := n - 1; i >= 0; i-- {
+		rTotal.series[i] = totalSupply.Sub(change).String()
+		rCir.series[i] = circulating.Sub(change).String()
+		amount, _ := decimal.NewFromString(burn.Amount[i])
+		change = change.Add(GetAmount(c.days[i].Unix(), issued).Sub(amount).Shift(int32(-c.digits)))
+
+		cumulative, _ := decimal.NewFromString(burn.Cumulative[i])
+		rBurned.series[i] = c.unit(cumulative)
+	}
+	c.metrics[CompareTotalSupply] = rTotal
+	c.metrics[CompareCirculating] = rCir
+	c.metrics[CompareBurned] = rBurned
+	return nil
+}
+
+// compareObjects the apps, contracts and tables of the ecosystem, the series go back from the current count
+// by the rows inserted in the later days(rollback_tx without the old data)
+func compareObjects(c *compareContext) error {
+	for name, table := range map[string]string{CompareApps: "1_applications", CompareContracts: "1_contracts", CompareTables: "1_tables"} {
+		var (
+			rets  compareMetric
+			total int64
+			list  []DaysNumber
+		)
+		if err := GetDB(nil).Table(`"`+table+`"`).Where("ecosystem = ?", c.ecosystem).Count(&total).Error; err != nil {
+			return err
+		}
+		err := GetDB(nil).Raw(`SELECT to_char(to_timestamp(bk.time),'yyyy-MM-dd') AS days,count(1) AS num FROM rollback_tx AS rt
+INNER JOIN "`+table+`" AS t ON(t.id::text = SPLIT_PART(rt.table_id,',',1))
+INNER JOIN block_chain AS bk ON(bk.id = rt.block_id)
+WHERE rt.table_name = ? AND rt.data = '' AND t.ecosystem = ? AND bk.time >= ? GROUP BY days`,
+			table, c.ecosystem, c.start().Unix()).Find(&list).Error
+		if err != nil {
+			return err
+		}
+		rets.value = strconv.FormatInt(total, 10)
+		rets.series = make([]string, len(c.days))
+		count := total
+		for i := len(c.days) - 1; i >= 0; i-- {
+			rets.series[i] = strconv.FormatInt(count, 10)
+			count -= GetDaysNumber(c.days[i].Unix(), list)
+		}
+		c.metrics[name] = rets
+	}
+	return nil
+}
+
+func compareDexReady() bool {
+	var p TokenPricePoint
+	return conf.GetEnvConf().Defi.Enable && AllowRankEcosystem > 0 && HasTableOrView(p.TableName())
+}
+
+// comparePrice the token price in the base token, the series are the last price of each day
+func comparePrice(c *compareContext) error {
+	rets := compareMetric{value: "0", series: make([]string, len(c.days))}
+	if c.ecosystem == AllowRankEcosystem {
+		rets.value = "1"
+	}
+	for i := range rets.series {
+		rets.series[i] = rets.value
+	}
+	if c.ecosystem == AllowRankEcosystem || !compareDexReady() {
+		c.metrics[ComparePrice] = rets
+		return nil
+	}
+
+	now, err := GetTokenPriceAt(c.ecosystem, GetNowTimeUnix())
+	if err != nil {
+		return err
+	}
+	prev, err := GetTokenPriceAt(c.ecosystem, c.start().Unix()-1)
+	if err != nil {
+		return err
+	}
+	var list []struct {
+		Days  string
+		Price decimal.Decimal
+	}
+	err = GetDB(nil).Raw(`SELECT DISTINCT ON(days) days,price FROM(
+	SELECT to_char(to_timestamp(time),'yyyy-MM-dd') AS days,price,time,block FROM token_price_point
+	WHERE ecosystem = ? AND time >= ? AND time < ? AND price > 0
+)AS v1 ORDER BY days,time DESC,block DESC`, c.ecosystem, c.start().Unix(), c.end().Unix()).Find(&list).Error
+	if err != nil {
+		return err
+	}
+	rets.value = now.Price
+	price := prev.Price
+	for i, d := range c.days {
+		day := d.Format("2006-01-02")
+		for _, v := range list {
+			if v.Days == day {
+				price = v.Price.String()
+			}
+		}
+		rets.series[i] = price
+	}
+	c.metrics[ComparePrice] = rets
+	return nil
+}
+
+// compareLiquidity the value locked in the pairs of the token and the base token, twice the base token reserves
+func compareLiquidity(c *compareContext) error {
+	rets := compareMetric{value: "0", series: make([]string, len(c.days))}
+	for i := range rets.series {
+		rets.series[i] = "0"
+	}
+	if !compareDexReady() {
+		c.metrics[CompareLiquidity] = rets
+		return nil
+	}
+	_, baseDigits := holderTokenInfo(AllowRankEcosystem)
+	value := func(reserve decimal.Decimal) string {
+		return reserve.Mul(decimal.NewFromInt(2)).Shift(int32(-baseDigits)).String()
+	}
+	baseReserve := func(ecosystem1 int64, reserve1, reserve2 decimal.Decimal) decimal.Decimal {
+		if ecosystem1 == AllowRankEcosystem {
+			return reserve1
+		}
+		return reserve2
+	}
+
+	allPair.RLock()
+	reserve := decimal.Zero
+	for _, v := range allPair.pairs {
+		if (v.Ecosystem1 == c.ecosystem || v.Ecosystem2 == c.ecosystem) &&
+			(v.Ecosystem1 == AllowRankEcosystem || v.Ecosystem2 == AllowRankEcosystem) {
+			reserve = reserve.Add(baseReserve(v.Ecosystem1, v.Reserve1, v.Reserve2))
+		}
+	}
+	allPair.RUnlock()
+	rets.value = value(reserve)
+
+	type pairReserve struct {
+		Days       string
+		PairId     int64
+		Ecosystem1 int64
+		Reserve1   decimal.Decimal
+		Reserve2   decimal.Decimal
+	}
+	var (
+		pair Pair
+		list []pairReserve
+	)
+	//the last reserves of each pair before the first day, then the last reserves of each pair in each day
+	err := GetDB(nil).Raw(`
+SELECT '' AS days,p.id AS pair_id,p.ecosystem1,tp.reserve1,tp.reserve2
+FROM "`+pair.TableName()+`" AS p CROSS JOIN LATERAL(
+	SELECT reserve1,reserve2 FROM token_price_point
+	WHERE pair_id = p.id AND (ecosystem = @eco OR @eco = @base) AND time < @start
+	ORDER BY block DESC LIMIT 1
+)AS tp
+UNION ALL
+(SELECT DISTINCT ON(days,pair_id) days,pair_id,ecosystem1,reserve1,reserve2 FROM(
+	SELECT to_char(to_timestamp(tp.time),'yyyy-MM-dd') AS days,
+		tp.pair_id,p.ecosystem1,tp.reserve1,tp.reserve2,tp.time,tp.block
+	FROM token_price_point AS tp INNER JOIN "`+pair.TableName()+`" AS p ON(p.id = tp.pair_id)
+	WHERE (tp.ecosystem = @eco OR @eco = @base) AND tp.time >= @start AND tp.time < @end
+)AS v1 ORDER BY days,pair_id,time DESC,block DESC)
+`, map[string]any{"eco": c.ecosystem, "base": AllowRankEcosystem, "start": c.start().Unix(), "end": c.end().Unix()}).Find(&list).Error
+	if err != nil {
+		return err
+	}
+	reserves := make(map[int64]decimal.Decimal)
+	update := func(day string) {
+		for _, v := range list {
+			if v.Days == day {
+				reserves[v.PairId] = baseReserve(v.Ecosystem1, v.Reserve1, v.Reserve2)
+			}
+		}
+	}
+	update("")
+	for i, d := range c.days {
+		update(d.Format("2006-01-02"))
+		sum := decimal.Zero
+		for _, v := range reserves {
+			sum = sum.Add(v)
+		}
+		rets.series[i] = value(sum)
+	}
+	c.metrics[CompareLiquidity] = rets
+	return nil
+}
@@ -354,6 +354,8 @@ func SyncEcosystemInfo() {
 	GetAllEcosystemInfo()
 	getEcosystemTxCount()
 	updateAllowRankEcosystem()
+	RefreshINameCache()
+	RefreshSearchIndex()
 }
 
 func GetAllKeysTotalAmount(ecosystem int64) error {
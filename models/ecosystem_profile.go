@@ -0,0 +1,293 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+)
+
+const (
+	ProfilePending  = 0
+	ProfileApproved = 1
+	ProfileRejected = 2
+
+	profileSignExpire     = 24 * 60 * 60
+	profileMaxDescription = 5000
+	profileMaxLinks       = 20
+	profileMaxUrl         = 500
+)
+
+// EcosystemProfileData the profile metadata signed by the founder. The signed message is the json of this object,
+// ecosystem and timestamp bind the signature to the ecosystem and prevent the replays
+type EcosystemProfileData struct {
+	Ecosystem   int64             `json:"ecosystem"`
+	Timestamp   int64             `json:"timestamp"`
+	Website     string            `json:"website"`
+	Description string            `json:"description"`
+	Socials     map[string]string `json:"socials"`
+	AuditLinks  []string          `json:"audit_links"`
+	Logo        string            `json:"logo"`
+}
+
+// EcosystemProfile a profile submission of an ecosystem, it is shown after an admin approves it
+type EcosystemProfile struct {
+	ID         int64  `gorm:"primary_key;not null" json:"id"`
+	Ecosystem  int64  `gorm:"not null;index" json:"ecosystem"`
+	Account    string `gorm:"not null" json:"account"` //the founder account that signed
+	Data       string `gorm:"not null;type:text" json:"data"`
+	Signature  string `gorm:"not null" json:"signature"`
+	SignTime   int64  `gorm:"not null" json:"sign_time"`
+	Status     int    `gorm:"not null;index" json:"status"` //0:pending 1:approved 2:rejected
+	ReviewNote string `gorm:"not null" json:"review_note"`
+	CreatedAt  int64  `gorm:"not null" json:"created_at"`
+	ReviewedAt int64  `gorm:"not null" json:"reviewed_at"`
+}
+
+// EcosystemProfileInfo the approved profile attached to the ecosystem detail
+type EcosystemProfileInfo struct {
+	Verified    bool              `json:"verified"`
+	Account     string            `json:"account"`
+	Website     string            `json:"website"`
+	Description string            `json:"description"`
+	Socials     map[string]string `json:"socials"`
+	AuditLinks  []string          `json:"audit_links"`
+	Logo        string            `json:"logo"`
+	SignTime    int64             `json:"sign_time"`
+	ReviewedAt  int64             `json:"reviewed_at"`
+}
+
+func (p *EcosystemProfile) TableName() string {
+	return "ecosystem_profile"
+}
+
+func (p *EcosystemProfile) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func InitEcosystemProfile() error {
+	var p EcosystemProfile
+	return p.CreateTable()
+}
+
+func (p *EcosystemProfile) Get(id int64) (bool, error) {
+	return isFound(GetDB(nil).Where("id = ?", id).Take(p))
+}
+
+func checkProfileUrl(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > profileMaxUrl {
+		return fmt.Errorf("%s too long", name)
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s %s invalid", name, value)
+	}
+	return nil
+}
+
+// Validate checks the profile metadata
+func (d *EcosystemProfileData) Validate() error {
+	d.Description = strings.TrimSpace(d.Description)
+	if len([]rune(d.Description)) > profileMaxDescription {
+		return errors.New("description too long")
+	}
+	if err := checkProfileUrl("website", d.Website); err != nil {
+		return err
+	}
+	if err := checkProfileUrl("logo", d.Logo); err != nil {
+		return err
+	}
+	if len(d.Socials) > profileMaxLinks || len(d.AuditLinks) > profileMaxLinks {
+		return errors.New("too many links")
+	}
+	for k, v := range d.Socials {
+		if err := checkProfileUrl("social "+k, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range d.AuditLinks {
+		if err := checkProfileUrl("audit link", v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getEcosystemFounder(ecosystem int64) (int64, error) {
+	var sp StateParameter
+	sp.ecosystem = ecosystem
+	f, err := sp.Get("founder_account")
+	if err != nil {
+		return 0, err
+	}
+	if !f || sp.Value == "" {
+		return 0, errors.New("ecosystem founder doesn't not exist")
+	}
+	founder, err := strconv.ParseInt(sp.Value, 10, 64)
+	if err != nil {
+		return 0, errors.New("ecosystem founder invalid")
+	}
+	return founder, nil
+}
+
+// verifyProfileSignature checks the signature of the data with the public key of the founder account
+func verifyProfileSignature(founder int64, data, signature string) error {
+	rets, err := VerifySignature(strconv.FormatInt(founder, 10), "", data, signature)
+	if err != nil {
+		return err
+	}
+	if !rets.Valid {
+		return errors.New("signature verify failed")
+	}
+	return nil
+}
+
+// SubmitEcosystemProfile verifies the founder signature and queues the profile for the moderation
+func SubmitEcosystemProfile(data, signature string) (*EcosystemProfile, error) {
+	var pd EcosystemProfileData
+	if err := json.Unmarshal([]byte(data), &pd); err != nil {
+		return nil, errors.New("profile data invalid")
+	}
+	if pd.Ecosystem <= 0 {
+		return nil, errors.New("ecosystem invalid")
+	}
+	if err := pd.Validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if pd.Timestamp > now+60 || now-pd.Timestamp > profileSignExpire {
+		return nil, errors.New("profile timestamp expired")
+	}
+	founder, err := getEcosystemFounder(pd.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifyProfileSignature(founder, data, signature); err != nil {
+		return nil, err
+	}
+
+	var last EcosystemProfile
+	f, err := isFound(GetDB(nil).Where("ecosystem = ?", pd.Ecosystem).Order("sign_time desc").Take(&last))
+	if err != nil {
+		return nil, err
+	}
+	if f && last.SignTime >= pd.Timestamp {
+		return nil, errors.New("a newer profile has been submitted")
+	}
+	p := EcosystemProfile{
+		Ecosystem: pd.Ecosystem,
+		Account:   converter.AddressToString(founder),
+		Data:      data,
+		Signature: strings.TrimPrefix(signature, "0x"),
+		SignTime:  pd.Timestamp,
+		Status:    ProfilePending,
+		CreatedAt: now,
+	}
+	if err = GetDB(nil).Create(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetEcosystemProfileList the profile submissions, status < 0 means all
+func GetEcosystemProfileList(ecosystem int64, status, page, limit int) (*GeneralResponse, error) {
+	var (
+		rets GeneralResponse
+		list []EcosystemProfile
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Model(&EcosystemProfile{})
+	if ecosystem > 0 {
+		query = query.Where("ecosystem = ?", ecosystem)
+	}
+	if status >= 0 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Order("id desc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	rets.List = list
+	return &rets, nil
+}
+
+// ReviewEcosystemProfile approves or rejects a pending profile
+func ReviewEcosystemProfile(id int64, approve bool, note string) (*EcosystemProfile, error) {
+	var p EcosystemProfile
+	f, err := p.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("profile doesn't not exist")
+	}
+	if p.Status != ProfilePending {
+		return nil, errors.New("profile has been reviewed")
+	}
+	p.Status = ProfileRejected
+	if approve {
+		p.Status = ProfileApproved
+	}
+	p.ReviewNote = strings.TrimSpace(note)
+	p.ReviewedAt = time.Now().Unix()
+	err = GetDB(nil).Model(&p).Updates(map[string]any{"status": p.Status, "review_note": p.ReviewNote, "reviewed_at": p.ReviewedAt}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetEcosystemProfile the latest approved profile of the ecosystem, verified is false if the founder changed after signing
+func GetEcosystemProfile(ecosystem int64) (*EcosystemProfileInfo, error) {
+	var p EcosystemProfile
+	if !HasTableOrView(p.TableName()) {
+		return nil, nil
+	}
+	f, err := isFound(GetDB(nil).Where("ecosystem = ? AND status = ?", ecosystem, ProfileApproved).Order("sign_time desc").Take(&p))
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, nil
+	}
+	var pd EcosystemProfileData
+	if err = json.Unmarshal([]byte(p.Data), &pd); err != nil {
+		return nil, err
+	}
+	rets := &EcosystemProfileInfo{
+		Account:     p.Account,
+		Website:     pd.Website,
+		Description: pd.Description,
+		Socials:     pd.Socials,
+		AuditLinks:  pd.AuditLinks,
+		Logo:        pd.Logo,
+		SignTime:    p.SignTime,
+		ReviewedAt:  p.ReviewedAt,
+	}
+	if founder, err := getEcosystemFounder(ecosystem); err == nil {
+		rets.Verified = converter.AddressToString(founder) == p.Account
+	}
+	return rets, nil
+}
@@ -0,0 +1,294 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	rolesTable             = "1_roles"
+	rolesParticipantsTable = "1_roles_participants"
+
+	RoleAssign   = "assign"
+	RoleUnassign = "unassign"
+	RoleUpdate   = "update"
+
+	PermissionTable     = "table"
+	PermissionContract  = "contract"
+	PermissionParameter = "parameter"
+)
+
+var (
+	roleAccessReg         = regexp.MustCompile(`RoleAccess\s*\(([^)]*)\)`)
+	contractConditionsReg = regexp.MustCompile(`ContractConditions\s*\(([^)]*)\)`)
+	conditionArgReg       = regexp.MustCompile(`"([^"]+)"|` + "`([^`]+)`" + `|(\d+)`)
+)
+
+type EcosystemRole struct {
+	Id          int64  `json:"id"`
+	RoleName    string `json:"role_name"`
+	RoleType    int64  `json:"role_type"`
+	Deleted     int64  `json:"deleted"`
+	Creator     string `json:"creator"` //json of the creator member
+	DateCreated int64  `json:"date_created"`
+	DateDeleted int64  `json:"date_deleted"`
+	Members     int64  `json:"members"` //active members
+}
+
+type EcosystemRoleMember struct {
+	Id          int64  `json:"id"`
+	Account     string `json:"account"`
+	MemberName  string `json:"member_name"`
+	Appointed   string `json:"appointed"` //json of the appointing member
+	DateCreated int64  `json:"date_created"`
+}
+
+type EcosystemRoleEvent struct {
+	RoleId   int64  `json:"role_id"`
+	RoleName string `json:"role_name"`
+	Account  string `json:"account"`
+	Event    string `json:"event"`
+	Block    int64  `json:"block"`
+	Hash     string `json:"hash"`
+	Time     int64  `json:"time"`
+}
+
+// PermissionObject a table, contract or parameter of the ecosystem and what its conditions refer to
+type PermissionObject struct {
+	Type       string           `json:"type"`
+	Name       string           `json:"name"`
+	Action     string           `json:"action"` //the permission of the table, e.g. insert, update, column name
+	Conditions string           `json:"conditions"`
+	Roles      []int64          `json:"roles"`     //role ids in RoleAccess
+	Contracts  []string         `json:"contracts"` //contract names in ContractConditions
+	RoleNames  map[int64]string `json:"role_names"`
+}
+
+type PermissionMatrixResponse struct {
+	Ecosystem int64              `json:"ecosystem"`
+	Roles     []EcosystemRole    `json:"roles"`
+	Objects   []PermissionObject `json:"objects"`
+	ByRole    map[int64][]string `json:"by_role"` //role id:type.name.action
+}
+
+// GetEcosystemRoles all roles of the ecosystem with the count of the active members
+func GetEcosystemRoles(ecosystem int64) ([]EcosystemRole, error) {
+	var list []EcosystemRole
+	err := GetDB(nil).Table(`"`+rolesTable+`" AS r`).Select(`r.id,r.role_name,r.role_type,r.deleted,COALESCE(r.creator::text,'') AS creator,
+	r.date_created,r.date_deleted,(SELECT count(1) FROM "`+rolesParticipantsTable+`" AS rp
+	WHERE rp.ecosystem = r.ecosystem AND rp.deleted = 0 AND rp.role->>'id' = r.id::text) AS members`).
+		Where("r.ecosystem = ?", ecosystem).Order("r.id asc").Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Ecosystem Roles Failed")
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetEcosystemRoleMembers the active members of the role
+func GetEcosystemRoleMembers(ecosystem, roleId int64, page, limit int) (*GeneralResponse, error) {
+	var (
+		rets GeneralResponse
+		list []EcosystemRoleMember
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Table(`"`+rolesParticipantsTable+`"`).
+		Where("ecosystem = ? AND deleted = 0 AND role->>'id' = ?", ecosystem, strconv.FormatInt(roleId, 10))
+	if err := query.Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	err := query.Select(`id,member->>'account' AS account,COALESCE(member->>'member_name','') AS member_name,
+	COALESCE(appointed::text,'') AS appointed,date_created`).
+		Order("date_created asc,id asc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem, "role": roleId}).Error("Get Ecosystem Role Members Failed")
+		return nil, err
+	}
+	rets.List = list
+	return &rets, nil
+}
+
+// GetEcosystemRoleHistory the assign and unassign events of the roles from rollback_tx: the insert of a participant
+// is the assign, the update that deleted it is the unassign. roleId 0 means all roles
+func GetEcosystemRoleHistory(ecosystem, roleId int64, page, limit int) (*GeneralResponse, error) {
+	var (
+		rets GeneralResponse
+		list []struct {
+			RoleId   int64
+			RoleName string
+			Account  string
+			Data     string
+			Block    int64
+			Hash     []byte
+			Time     int64
+		}
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Table(`rollback_tx AS rt`).
+		Joins(`INNER JOIN "`+rolesParticipantsTable+`" AS rp ON(rp.id::text = SPLIT_PART(rt.table_id,',',1))`).
+		Joins("LEFT JOIN block_chain AS bk ON(bk.id = rt.block_id)").
+		Where("rt.table_name = ? AND rp.ecosystem = ?", rolesParticipantsTable, ecosystem)
+	if roleId > 0 {
+		query = query.Where("rp.role->>'id' = ?", strconv.FormatInt(roleId, 10))
+	}
+	if err := query.Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	err := query.Select(`CAST(COALESCE(NULLIF(rp.role->>'id',''),'0') AS BIGINT) AS role_id,COALESCE(rp.role->>'name','') AS role_name,
+	COALESCE(rp.member->>'account','') AS account,rt.data,rt.block_id AS block,rt.tx_hash AS hash,COALESCE(bk.time,0) AS time`).
+		Order("rt.id desc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem, "role": roleId}).Error("Get Ecosystem Role History Failed")
+		return nil, err
+	}
+	events := make([]EcosystemRoleEvent, len(list))
+	for i, v := range list {
+		events[i] = EcosystemRoleEvent{
+			RoleId:   v.RoleId,
+			RoleName: v.RoleName,
+			Account:  v.Account,
+			Event:    roleEvent(v.Data),
+			Block:    v.Block,
+			Hash:     hex.EncodeToString(v.Hash),
+			Time:     v.Time,
+		}
+	}
+	rets.List = events
+	return &rets, nil
+}
+
+// roleEvent the event of a participant change by the old values in the rollback data
+func roleEvent(data string) string {
+	if data == "" {
+		return RoleAssign
+	}
+	var old map[string]any
+	if err := json.Unmarshal([]byte(data), &old); err != nil {
+		return RoleUpdate
+	}
+	if v, ok := old["deleted"]; ok && parameterValueString(v) == "0" {
+		return RoleUnassign
+	}
+	if v, ok := old["deleted"]; ok && parameterValueString(v) == "1" {
+		return RoleAssign
+	}
+	return RoleUpdate
+}
+
+func conditionArgs(reg *regexp.Regexp, conditions string) []string {
+	var rets []string
+	for _, m := range reg.FindAllStringSubmatch(conditions, -1) {
+		for _, arg := range conditionArgReg.FindAllStringSubmatch(m[1], -1) {
+			for _, v := range arg[1:] {
+				if v != "" {
+					rets = append(rets, v)
+					break
+				}
+			}
+		}
+	}
+	return rets
+}
+
+func newPermissionObject(tp, name, action, conditions string, roleNames map[int64]string) (PermissionObject, bool) {
+	obj := PermissionObject{Type: tp, Name: name, Action: action, Conditions: conditions, RoleNames: make(map[int64]string)}
+	for _, v := range conditionArgs(roleAccessReg, conditions) {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		obj.Roles = append(obj.Roles, id)
+		obj.RoleNames[id] = roleNames[id]
+	}
+	obj.Contracts = conditionArgs(contractConditionsReg, conditions)
+	return obj, len(obj.Roles) > 0 || len(obj.Contracts) > 0
+}
+
+// GetPermissionMatrix the roles and contracts referred by the conditions of the tables, contracts and parameters of the ecosystem
+func GetPermissionMatrix(ecosystem int64) (*PermissionMatrixResponse, error) {
+	var rets PermissionMatrixResponse
+	rets.Ecosystem = ecosystem
+	roles, err := GetEcosystemRoles(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	rets.Roles = roles
+	roleNames := make(map[int64]string)
+	for _, v := range roles {
+		roleNames[v.Id] = v.RoleName
+	}
+
+	add := func(tp, name, action, conditions string) {
+		if obj, ok := newPermissionObject(tp, name, action, conditions, roleNames); ok {
+			rets.Objects = append(rets.Objects, obj)
+		}
+	}
+
+	var tables []struct {
+		Name        string
+		Permissions string
+		Columns     string
+		Conditions  string
+	}
+	err = GetDB(nil).Table(`"1_tables"`).Select("name,COALESCE(permissions::text,'') AS permissions,COALESCE(columns::text,'') AS columns,conditions").
+		Where("ecosystem = ?", ecosystem).Order("name asc").Find(&tables).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Permission Tables Failed")
+		return nil, err
+	}
+	for _, t := range tables {
+		add(PermissionTable, t.Name, "conditions", t.Conditions)
+		for _, field := range []string{t.Permissions, t.Columns} {
+			var m map[string]string
+			if err := json.Unmarshal([]byte(field), &m); err != nil {
+				continue
+			}
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				add(PermissionTable, t.Name, k, m[k])
+			}
+		}
+	}
+
+	var contracts []Contract
+	if err = GetDB(nil).Select("name,conditions").Where("ecosystem = ?", ecosystem).Order("name asc").Find(&contracts).Error; err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Permission Contracts Failed")
+		return nil, err
+	}
+	for _, c := range contracts {
+		add(PermissionContract, c.Name, "conditions", c.Conditions)
+	}
+
+	var params []StateParameter
+	if err = GetDB(nil).Select("name,conditions").Where("ecosystem = ?", ecosystem).Order("name asc").Find(&params).Error; err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Permission Parameters Failed")
+		return nil, err
+	}
+	for _, p := range params {
+		add(PermissionParameter, p.Name, "conditions", p.Conditions)
+	}
+
+	rets.ByRole = make(map[int64][]string)
+	for _, obj := range rets.Objects {
+		for _, id := range obj.Roles {
+			rets.ByRole[id] = append(rets.ByRole[id], obj.Type+"."+obj.Name+"."+obj.Action)
+		}
+	}
+	return &rets, nil
+}
@@ -0,0 +1,68 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoleEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "inserted participant", data: "", want: RoleAssign},
+		{name: "deleted participant", data: `{"deleted":"0"}`, want: RoleUnassign},
+		{name: "deleted participant number", data: `{"deleted":0}`, want: RoleUnassign},
+		{name: "restored participant", data: `{"deleted":"1","date_deleted":"1700000000"}`, want: RoleAssign},
+		{name: "other fields changed", data: `{"appointed":"{}"}`, want: RoleUpdate},
+		{name: "invalid rollback data", data: `{`, want: RoleUpdate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roleEvent(tt.data); got != tt.want {
+				t.Fatalf("roleEvent(%s) = %s, want %s", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPermissionObject(t *testing.T) {
+	roleNames := map[int64]string{1: "Admin", 3: "Developer"}
+	tests := []struct {
+		name       string
+		conditions string
+		roles      []int64
+		contracts  []string
+		ok         bool
+	}{
+		{name: "role access", conditions: `RoleAccess(1, 3)`, roles: []int64{1, 3}, ok: true},
+		{name: "contract conditions", conditions: `ContractConditions("MainCondition", ` + "`DeveloperCondition`" + `)`,
+			contracts: []string{"MainCondition", "DeveloperCondition"}, ok: true},
+		{name: "both", conditions: `RoleAccess(3) || ContractConditions("@1AdminCondition")`,
+			roles: []int64{3}, contracts: []string{"@1AdminCondition"}, ok: true},
+		{name: "non numeric role is skipped", conditions: `RoleAccess("admin")`},
+		{name: "true", conditions: `true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, ok := newPermissionObject("table", "@1keys", "update", tt.conditions, roleNames)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(obj.Roles, tt.roles) || !reflect.DeepEqual(obj.Contracts, tt.contracts) {
+				t.Fatalf("roles %v contracts %v, want %v %v", obj.Roles, obj.Contracts, tt.roles, tt.contracts)
+			}
+			for _, id := range obj.Roles {
+				if obj.RoleNames[id] != roleNames[id] {
+					t.Errorf("role %d name = %s", id, obj.RoleNames[id])
+				}
+			}
+		})
+	}
+}
@@ -715,6 +715,10 @@ func GetEcosystemDetailInfo(search any) (*EcosystemDetailInfoResponse, error) {
 		}
 		rets.Hash = hex.EncodeToString(ts.Hash)
 	}
+	rets.Profile, err = GetEcosystemProfile(eco.ID)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": eco.ID}).Error("Get Ecosystem Profile Failed")
+	}
 
 	return &rets, nil
 }
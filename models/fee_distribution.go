@@ -0,0 +1,258 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FeeKindFees    = "fees"
+	FeeKindTaxes   = "taxes"
+	FeeKindStartUp = "start_up"
+
+	feeChartMaxDays = 365
+)
+
+// feeFlowSQL the fee movements of an ecosystem: fees(1_history type 1,spent_info_history type 3),
+// taxes(1_history type 2,spent_info_history type 4) and startUp(spent_info_history type 5)
+const feeFlowSQL = `(
+	SELECT recipient_id AS key_id,amount,created_at,block_id AS block,CASE WHEN type = 1 THEN 'fees' ELSE 'taxes' END AS kind
+		FROM "1_history" WHERE type IN(1,2) AND ecosystem = ?
+	UNION ALL
+	SELECT recipient_id AS key_id,amount,created_at,block,CASE WHEN type = 3 THEN 'fees' WHEN type = 4 THEN 'taxes' ELSE 'start_up' END AS kind
+		FROM spent_info_history WHERE type IN(3,4,5) AND ecosystem = ?
+)`
+
+type FeeRecipient struct {
+	Account string            `json:"account"`
+	Kind    string            `json:"kind"`
+	Tx      int64             `json:"tx"`
+	Amount  decimal.Decimal   `json:"amount"`
+	Iname   string            `json:"iname,omitempty" gorm:"-"`
+	Label   *AddressLabelInfo `json:"label,omitempty" gorm:"-"`
+}
+
+type FeeComponentChartResponse struct {
+	TokenSymbol string   `json:"token_symbol"`
+	Name        string   `json:"name"` //ecosystem name
+	Digits      int      `json:"digits"`
+	Time        []int64  `json:"time"`
+	Fees        []string `json:"fees"`
+	Taxes       []string `json:"taxes"`
+	StartUp     []string `json:"start_up"`
+	Combustion  []string `json:"combustion"`
+	VmCostFee   []string `json:"vm_cost_fee"`
+	ElementFee  []string `json:"element_fee"`
+	StorageFee  []string `json:"storage_fee"`
+	ExpediteFee []string `json:"expedite_fee"`
+}
+
+type NodeFeeIncome struct {
+	Days          string          `json:"days"`
+	NodePosition  int64           `json:"node_position"`
+	ConsensusMode int32           `json:"consensus_mode"`
+	NodeName      string          `json:"node_name" gorm:"-"`
+	Tx            int64           `json:"tx"`
+	Amount        decimal.Decimal `json:"amount"`
+}
+
+type NodeFeeIncomeResponse struct {
+	TokenSymbol string          `json:"token_symbol"`
+	Digits      int             `json:"digits"`
+	List        []NodeFeeIncome `json:"list"`
+}
+
+// feeDetailAmountSQL the charged amount of a fee in value_detail, a converted fee is charged at conversion_rate percent of the value
+func feeDetailAmountSQL(name string) string {
+	detail := "value_detail::jsonb->'" + name + "'"
+	return `CASE WHEN COALESCE(` + detail + `->>'convert','false') = 'true'
+		THEN CAST(NULLIF(` + detail + `->>'value','') AS numeric)*CAST(COALESCE(NULLIF(` + detail + `->>'conversion_rate',''),'0') AS numeric)/100
+		ELSE CAST(NULLIF(` + detail + `->>'value','') AS numeric) END`
+}
+
+func feeTimeRange(startTime, endTime int64) (int64, int64, error) {
+	if endTime <= 0 {
+		endTime = GetNowTimeUnix()
+	}
+	if startTime <= 0 {
+		startTime = endTime - 7*86400
+	}
+	if startTime > endTime || endTime-startTime > feeChartMaxDays*86400 {
+		return 0, 0, errors.New("time range invalid")
+	}
+	return startTime, endTime, nil
+}
+
+// GetFeeRecipients the accounts that receive the fees, taxes and startUp of the ecosystem in the time range
+func GetFeeRecipients(ecosystem, startTime, endTime int64, page, limit int) (*GeneralResponse, error) {
+	start, end, err := feeTimeRange(startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	type recipient struct {
+		KeyId  int64
+		Kind   string
+		Tx     int64
+		Amount decimal.Decimal
+	}
+	var (
+		rets GeneralResponse
+		list []recipient
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Table(feeFlowSQL+" AS v1", ecosystem, ecosystem).
+		Where("created_at >= ? AND created_at <= ?", start*1000, end*1000)
+	err = GetDB(nil).Table("(?) AS v2", query.Select("key_id,kind").Group("key_id,kind")).Count(&rets.Total).Error
+	if err != nil {
+		return nil, err
+	}
+	err = GetDB(nil).Table(feeFlowSQL+" AS v1", ecosystem, ecosystem).
+		Where("created_at >= ? AND created_at <= ?", start*1000, end*1000).
+		Select("key_id,kind,count(1) AS tx,sum(amount) AS amount").Group("key_id,kind").
+		Order("amount desc,key_id asc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Fee Recipients Failed")
+		return nil, err
+	}
+
+	items := make([]FeeRecipient, len(list))
+	for i, v := range list {
+		account := converter.AddressToString(v.KeyId)
+		items[i] = FeeRecipient{
+			Account: account,
+			Kind:    v.Kind,
+			Tx:      v.Tx,
+			Amount:  v.Amount,
+			Iname:   GetIName(account),
+			Label:   AddressLabels.Label(account),
+		}
+	}
+	rets.List = items
+	return &rets, nil
+}
+
+// GetFeeComponentChart the daily fees, taxes, startUp and combustion, and the vm, element, storage and expedite split of the fees
+func GetFeeComponentChart(ecosystem int64, days int) (*FeeComponentChartResponse, error) {
+	if days <= 0 || days > feeChartMaxDays {
+		return nil, errors.New("days invalid")
+	}
+	type kindAmount struct {
+		Days   string
+		Kind   string
+		Amount decimal.Decimal
+	}
+	type componentAmount struct {
+		Days     string
+		VmCost   decimal.Decimal
+		Element  decimal.Decimal
+		Storage  decimal.Decimal
+		Expedite decimal.Decimal
+	}
+	var (
+		rets       FeeComponentChartResponse
+		kinds      []kindAmount
+		combustion []DaysAmount
+		components []componentAmount
+	)
+	rets.Name = EcoNames.Get(ecosystem)
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(ecosystem)
+
+	tz := time.Unix(GetNowTimeUnix(), 0)
+	today := time.Date(tz.Year(), tz.Month(), tz.Day(), 0, 0, 0, 0, tz.Location())
+	start := today.AddDate(0, 0, -1*(days-1)).UnixMilli()
+
+	err := GetDB(nil).Table(feeFlowSQL+" AS v1", ecosystem, ecosystem).
+		Select("to_char(to_timestamp(created_at/1000),'yyyy-MM-dd') AS days,kind,sum(amount) AS amount").
+		Where("created_at >= ?", start).Group("days,kind").Find(&kinds).Error
+	if err != nil {
+		return nil, err
+	}
+	//the fee combustion, the token burns and the transfers to the black hole address are not fees
+	err = burnLedger(GetDB(nil), ecosystem).
+		Select("to_char(to_timestamp(created_at/1000),'yyyy-MM-dd') AS days,sum(amount) AS amount").
+		Where("created_at >= ? AND source IN(?,?)", start, BurnSourceContract, BurnSourceUtxo).Group("days").Find(&combustion).Error
+	if err != nil {
+		return nil, err
+	}
+	err = GetDB(nil).Raw(`
+SELECT to_char(to_timestamp(created_at/1000),'yyyy-MM-dd') AS days,
+	COALESCE(sum(`+feeDetailAmountSQL("vmCost_fee")+`),0) AS vm_cost,
+	COALESCE(sum(`+feeDetailAmountSQL("element_fee")+`),0) AS element,
+	COALESCE(sum(`+feeDetailAmountSQL("storage_fee")+`),0) AS storage,
+	COALESCE(sum(`+feeDetailAmountSQL("expedite_fee")+`),0) AS expedite
+FROM "1_history" WHERE type = 1 AND ecosystem = ? AND created_at >= ? AND value_detail <> '' GROUP BY days
+`, ecosystem, start).Find(&components).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Fee Component Chart Failed")
+		return nil, err
+	}
+
+	getKind := func(day, kind string) string {
+		for _, v := range kinds {
+			if v.Days == day && v.Kind == kind {
+				return v.Amount.String()
+			}
+		}
+		return "0"
+	}
+	getComponent := func(day string) componentAmount {
+		for _, v := range components {
+			if v.Days == day {
+				return v
+			}
+		}
+		return componentAmount{}
+	}
+	for t := today.AddDate(0, 0, -1*(days-1)); !t.After(today); t = t.AddDate(0, 0, 1) {
+		day := t.Format("2006-01-02")
+		cp := getComponent(day)
+		rets.Time = append(rets.Time, t.Unix())
+		rets.Fees = append(rets.Fees, getKind(day, FeeKindFees))
+		rets.Taxes = append(rets.Taxes, getKind(day, FeeKindTaxes))
+		rets.StartUp = append(rets.StartUp, getKind(day, FeeKindStartUp))
+		rets.Combustion = append(rets.Combustion, GetDaysAmount(t.Unix(), combustion))
+		rets.VmCostFee = append(rets.VmCostFee, cp.VmCost.String())
+		rets.ElementFee = append(rets.ElementFee, cp.Element.String())
+		rets.StorageFee = append(rets.StorageFee, cp.Storage.String())
+		rets.ExpediteFee = append(rets.ExpediteFee, cp.Expedite.String())
+	}
+	return &rets, nil
+}
+
+// GetNodeFeeIncome the daily fee income of the nodes, only the fees of a block received by the node that packed it are counted
+func GetNodeFeeIncome(ecosystem, startTime, endTime int64) (*NodeFeeIncomeResponse, error) {
+	start, end, err := feeTimeRange(startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	var rets NodeFeeIncomeResponse
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(ecosystem)
+	err = GetDB(nil).Table(feeFlowSQL+" AS v1", ecosystem, ecosystem).
+		Select("to_char(to_timestamp(bk.time),'yyyy-MM-dd') AS days,bk.node_position,bk.consensus_mode,count(1) AS tx,sum(v1.amount) AS amount").
+		Joins("INNER JOIN block_chain AS bk ON(bk.id = v1.block AND bk.key_id = v1.key_id)").
+		Where("v1.kind = ? AND v1.created_at >= ? AND v1.created_at <= ?", FeeKindFees, start*1000, end*1000).
+		Group("days,bk.node_position,bk.consensus_mode").Order("days desc,amount desc").Find(&rets.List).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("Get Node Fee Income Failed")
+		return nil, err
+	}
+	for i := 0; i < len(rets.List); i++ {
+		for _, node := range HonorNodes {
+			if node.NodePosition == rets.List[i].NodePosition && node.ConsensusMode == rets.List[i].ConsensusMode {
+				rets.List[i].NodeName = node.NodeName
+				break
+			}
+		}
+	}
+	return &rets, nil
+}
@@ -0,0 +1,192 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const feeEstimateSamples = 200
+
+type FeeComponentEstimate struct {
+	VmCostFee   string `json:"vm_cost_fee"`
+	ElementFee  string `json:"element_fee"`
+	StorageFee  string `json:"storage_fee"`
+	ExpediteFee string `json:"expedite_fee"`
+}
+
+// FeeSplitEstimate the split of the median fee when the ecosystem burns a percent of the fees
+type FeeSplitEstimate struct {
+	Combustion string `json:"combustion"`
+	Recipient  string `json:"recipient"`
+}
+
+type FeeEstimateResponse struct {
+	Contract          string               `json:"contract"`
+	Ecosystem         int64                `json:"ecosystem"` //the paying ecosystem
+	TokenSymbol       string               `json:"token_symbol"`
+	Digits            int                  `json:"digits"`
+	FuelRate          string               `json:"fuel_rate"`
+	Samples           int                  `json:"samples"`
+	P10               string               `json:"p10"`
+	P50               string               `json:"p50"`
+	P90               string               `json:"p90"`
+	Components        FeeComponentEstimate `json:"components"` //median of each component
+	CombustionPercent int                  `json:"combustion_percent"`
+	Split             FeeSplitEstimate     `json:"split"`
+}
+
+type feeSample struct {
+	VmCost   decimal.Decimal
+	Element  decimal.Decimal
+	Storage  decimal.Decimal
+	Expedite decimal.Decimal
+	Total    decimal.Decimal
+}
+
+func feePercentile(list []decimal.Decimal, p int) decimal.Decimal {
+	if len(list) == 0 {
+		return decimal.Zero
+	}
+	idx := (len(list) - 1) * p / 100
+	return list[idx]
+}
+
+func feeMedian(list []decimal.Decimal) decimal.Decimal {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LessThan(list[j])
+	})
+	return feePercentile(list, 50)
+}
+
+// feeDetailCharged the charged amount of a fee component, a converted fee is charged at conversion_rate percent of the value
+func feeDetailCharged(fe FeeDetail, value decimal.Decimal) decimal.Decimal {
+	if fe.Convert {
+		return value.Mul(decimal.NewFromFloat(fe.ConversionRate)).Div(decimal.NewFromInt(100)).Floor()
+	}
+	return value
+}
+
+// feeModeDetail the fee mode of a component, the fee is converted with the conversion rate when the flag is above 1
+func feeModeDetail(flag int64, conversionRate string) FeeDetail {
+	rate, _ := strconv.ParseFloat(conversionRate, 64)
+	return FeeDetail{Flag: int(flag), Convert: flag > 1, ConversionRate: rate}
+}
+
+// feeCombustionSplit splits the fee into the burned part and the part received by the fee recipient
+func feeCombustionSplit(fee decimal.Decimal, percent int64) FeeSplitEstimate {
+	combustion := fee.Mul(decimal.NewFromInt(percent)).Div(decimal.NewFromInt(100)).Floor()
+	return FeeSplitEstimate{Combustion: combustion.String(), Recipient: fee.Sub(combustion).String()}
+}
+
+// GetFeeEstimate estimates the fee of a contract from its recent executions in any ecosystem.
+// The fuel of each fee component is the recorded value divided by the fuel rate of that time, the conversion of the
+// recorded ecosystem is not used. The fuel is valued with the current fuel rate of the paying ecosystem and the conversion
+// rate of its fee mode. The combustion of the ecosystem fee mode splits the median fee like the chain does
+func GetFeeEstimate(contract string, ecosystem int64) (*FeeEstimateResponse, error) {
+	var rets FeeEstimateResponse
+	rates := GetFuelRate()
+	rate, ok := rates[ecosystem]
+	if !ok || rate.IsZero() {
+		return nil, errors.New("fuel rate of the ecosystem doesn't not exist")
+	}
+	rets.Contract = contract
+	rets.Ecosystem = ecosystem
+	rets.FuelRate = rate.String()
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(ecosystem)
+
+	var (
+		feeInfo FeeModeInfo
+		modes   = make(map[string]FeeDetail)
+	)
+	if ecosystem != 1 {
+		var eco Ecosystem
+		f, err := isFound(GetDB(nil).Select("fee_mode_info").Where("id = ?", ecosystem).Take(&eco))
+		if err != nil {
+			return nil, err
+		}
+		if f && eco.FeeModeInfo != "" {
+			if err := json.Unmarshal([]byte(eco.FeeModeInfo), &feeInfo); err != nil {
+				log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Warn("Get Fee Estimate Fee Mode Failed")
+			}
+		}
+		for key, v := range feeInfo.FeeModeDetail {
+			modes[key] = feeModeDetail(v.FlagToInt(), v.ConversionRate)
+		}
+	}
+
+	var details []string
+	err := GetDB(nil).Table(`"1_history" AS h1`).Select("h1.value_detail").
+		Joins("LEFT JOIN log_transactions AS lt ON(lt.hash = h1.txhash)").
+		Where("h1.type = 1 AND h1.value_detail <> '' AND lt.contract_name = ?", contract).
+		Order("h1.id desc").Limit(feeEstimateSamples).Pluck("h1.value_detail", &details).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "contract": contract}).Error("Get Fee Estimate Samples Failed")
+		return nil, err
+	}
+
+	fuel := func(key string, fe FeeDetail, fuelRate decimal.Decimal) decimal.Decimal {
+		value, _ := decimal.NewFromString(fe.Value)
+		return feeDetailCharged(modes[key], value.DivRound(fuelRate, 0).Mul(rate))
+	}
+	var samples []feeSample
+	for _, v := range details {
+		var fd fuelDetail
+		if err := json.Unmarshal([]byte(v), &fd); err != nil {
+			continue
+		}
+		fuelRate, _ := decimal.NewFromString(fd.FuelRate)
+		if fuelRate.IsZero() {
+			continue
+		}
+		s := feeSample{
+			VmCost:   fuel("vmCost_fee", fd.VmCostFee, fuelRate),
+			Element:  fuel("element_fee", fd.ElementFee, fuelRate),
+			Storage:  fuel("storage_fee", fd.StorageFee, fuelRate),
+			Expedite: fuel("expedite_fee", fd.ExpediteFee, fuelRate),
+		}
+		s.Total = s.VmCost.Add(s.Element).Add(s.Storage).Add(s.Expedite)
+		samples = append(samples, s)
+	}
+	rets.Samples = len(samples)
+	if len(samples) == 0 {
+		return nil, errors.New("contract executions doesn't not exist")
+	}
+
+	var totals, vm, element, storage, expedite []decimal.Decimal
+	for _, s := range samples {
+		totals = append(totals, s.Total)
+		vm = append(vm, s.VmCost)
+		element = append(element, s.Element)
+		storage = append(storage, s.Storage)
+		expedite = append(expedite, s.Expedite)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].LessThan(totals[j])
+	})
+	rets.P10 = feePercentile(totals, 10).String()
+	rets.P50 = feePercentile(totals, 50).String()
+	rets.P90 = feePercentile(totals, 90).String()
+	rets.Components = FeeComponentEstimate{
+		VmCostFee:   feeMedian(vm).String(),
+		ElementFee:  feeMedian(element).String(),
+		StorageFee:  feeMedian(storage).String(),
+		ExpediteFee: feeMedian(expedite).String(),
+	}
+
+	rets.Split = FeeSplitEstimate{Combustion: "0", Recipient: rets.P50}
+	if feeInfo.Combustion.Flag > 1 {
+		rets.CombustionPercent = int(feeInfo.Combustion.Percent)
+		rets.Split = feeCombustionSplit(feePercentile(totals, 50), feeInfo.Combustion.Percent)
+	}
+	return &rets, nil
+}
@@ -0,0 +1,95 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFeeDetailCharged(t *testing.T) {
+	tests := []struct {
+		name string
+		fe   FeeDetail
+		want string
+	}{
+		{name: "not converted", fe: FeeDetail{Flag: 1, ConversionRate: 50}, want: "1000"},
+		{name: "converted at half", fe: FeeDetail{Flag: 2, Convert: true, ConversionRate: 50}, want: "500"},
+		{name: "converted up", fe: FeeDetail{Flag: 2, Convert: true, ConversionRate: 250.5}, want: "2505"},
+		{name: "converted floor", fe: FeeDetail{Flag: 2, Convert: true, ConversionRate: 33.33}, want: "333"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := feeDetailCharged(tt.fe, decimal.NewFromInt(1000)); got.String() != tt.want {
+				t.Fatalf("charged = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeeModeDetail(t *testing.T) {
+	tests := []struct {
+		flag int64
+		rate string
+		want FeeDetail
+	}{
+		{flag: 0, rate: "", want: FeeDetail{}},
+		{flag: 1, rate: "50", want: FeeDetail{Flag: 1, ConversionRate: 50}},
+		{flag: 2, rate: "250.5", want: FeeDetail{Flag: 2, Convert: true, ConversionRate: 250.5}},
+		{flag: 2, rate: "bad", want: FeeDetail{Flag: 2, Convert: true}},
+	}
+	for _, tt := range tests {
+		if got := feeModeDetail(tt.flag, tt.rate); got != tt.want {
+			t.Errorf("feeModeDetail(%d,%q) = %+v, want %+v", tt.flag, tt.rate, got, tt.want)
+		}
+	}
+	//the recorded conversion of a sample is not used, the current mode of the paying ecosystem is applied once
+	recorded := FeeDetail{Flag: 2, Value: "1000", Convert: true, ConversionRate: 10}
+	value := decimal.RequireFromString(recorded.Value)
+	if got := feeDetailCharged(feeModeDetail(1, ""), value); got.String() != "1000" {
+		t.Errorf("charged without conversion = %s, want 1000", got)
+	}
+	if got := feeDetailCharged(feeModeDetail(2, "50"), value); got.String() != "500" {
+		t.Errorf("charged with the current conversion = %s, want 500", got)
+	}
+}
+
+func TestFeeCombustionSplit(t *testing.T) {
+	tests := []struct {
+		fee        int64
+		percent    int64
+		combustion string
+		recipient  string
+	}{
+		{fee: 1000, percent: 0, combustion: "0", recipient: "1000"},
+		{fee: 1000, percent: 30, combustion: "300", recipient: "700"},
+		{fee: 999, percent: 50, combustion: "499", recipient: "500"},
+		{fee: 1000, percent: 100, combustion: "1000", recipient: "0"},
+	}
+	for _, tt := range tests {
+		got := feeCombustionSplit(decimal.NewFromInt(tt.fee), tt.percent)
+		if got.Combustion != tt.combustion || got.Recipient != tt.recipient {
+			t.Errorf("split(%d,%d) = %+v", tt.fee, tt.percent, got)
+		}
+	}
+}
+
+func TestFeePercentile(t *testing.T) {
+	var list []decimal.Decimal
+	for _, v := range []int64{9, 1, 5, 3, 7, 2, 8, 4, 6, 10} {
+		list = append(list, decimal.NewFromInt(v))
+	}
+	if got := feeMedian(list); got.String() != "5" {
+		t.Fatalf("median = %s", got)
+	}
+	if got := feePercentile(list, 90); got.String() != "9" {
+		t.Fatalf("p90 = %s", got)
+	}
+	if got := feePercentile(nil, 50); !got.IsZero() {
+		t.Fatalf("empty percentile = %s", got)
+	}
+}
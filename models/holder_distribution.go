@@ -0,0 +1,417 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	//holderRankSnapshotTop the ranks of top holders kept daily to calculate the rank change
+	holderRankSnapshotTop = 1000
+	//holderRankSnapshotDays snapshots older than this are deleted
+	holderRankSnapshotDays = 8
+)
+
+// holderBuckets balance bucket lower bound, in token unit
+var holderBuckets = []int64{0, 1, 10, 100, 1000, 10000, 100000, 1000000}
+
+// HolderDistributionReport daily distribution statistics of ecosystem token
+type HolderDistributionReport struct {
+	ID          int64  `gorm:"primary_key;not null" json:"id"`
+	Ecosystem   int64  `gorm:"not null;uniqueIndex:idx_holder_distribution_eco_time" json:"ecosystem"`
+	Time        int64  `gorm:"not null;uniqueIndex:idx_holder_distribution_eco_time" json:"time"`
+	Holders     int64  `gorm:"not null" json:"holders"`
+	TotalAmount string `gorm:"type:decimal(30);not null" json:"total_amount"`
+	Top10Share  string `gorm:"type:varchar(30);not null" json:"top10_share"`
+	Top100Share string `gorm:"type:varchar(30);not null" json:"top100_share"`
+	Gini        string `gorm:"type:varchar(30);not null" json:"gini"`
+	Buckets     string `gorm:"type:jsonb;not null" json:"-"`
+}
+
+// HolderRankSnapshot daily rank of the top holders
+type HolderRankSnapshot struct {
+	ID        int64  `gorm:"primary_key;not null"`
+	Ecosystem int64  `gorm:"not null;index:idx_holder_rank_eco_time"`
+	Time      int64  `gorm:"not null;index:idx_holder_rank_eco_time"`
+	Account   string `gorm:"not null"`
+	Rank      int64  `gorm:"not null"`
+}
+
+type HolderBucket struct {
+	Min     int64  `json:"min"`
+	Max     int64  `json:"max"` //0:unlimited
+	Holders int64  `json:"holders"`
+	Amount  string `json:"amount"`
+}
+
+type HolderDistribution struct {
+	Ecosystem   int64           `json:"ecosystem"`
+	TokenSymbol string          `json:"token_symbol"`
+	Digits      int             `json:"digits"`
+	Time        int64           `json:"time"`
+	Holders     int64           `json:"holders"`
+	TotalAmount decimal.Decimal `json:"total_amount"`
+	Top10Share  decimal.Decimal `json:"top10_share"`
+	Top100Share decimal.Decimal `json:"top100_share"`
+	Gini        decimal.Decimal `json:"gini"`
+	Buckets     []HolderBucket  `json:"buckets"`
+}
+
+type HolderRichList struct {
+	Rank         int64             `json:"rank"`
+	Account      string            `json:"account"`
+	Iname        string            `json:"iname,omitempty" gorm:"-"`
+	Label        *AddressLabelInfo `json:"label,omitempty" gorm:"-"`
+	TotalAmount  decimal.Decimal   `json:"total_amount"`
+	StakeAmount  decimal.Decimal   `json:"stake_amount"`
+	AccountedFor decimal.Decimal   `json:"accounted_for"`
+	Change24h    *int64            `json:"change_24h" gorm:"-"` //rank change, null if not ranked at that time
+	Change7d     *int64            `json:"change_7d" gorm:"-"`
+	TokenSymbol  string            `json:"token_symbol"`
+	Digits       int               `json:"digits"`
+}
+
+type HolderDistributionChart struct {
+	Ecosystem   int64    `json:"ecosystem"`
+	Time        []int64  `json:"time"`
+	Holders     []int64  `json:"holders"`
+	Top10Share  []string `json:"top10_share"`
+	Top100Share []string `json:"top100_share"`
+	Gini        []string `json:"gini"`
+}
+
+func (p *HolderDistributionReport) TableName() string {
+	return "holder_distribution_report"
+}
+
+func (p *HolderDistributionReport) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (p *HolderRankSnapshot) TableName() string {
+	return "holder_rank_snapshot"
+}
+
+func (p *HolderRankSnapshot) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func InitHolderDistribution() error {
+	var (
+		hd HolderDistributionReport
+		hr HolderRankSnapshot
+	)
+	if err := hd.CreateTable(); err != nil {
+		return err
+	}
+	return hr.CreateTable()
+}
+
+// holderQuery the holders of the ecosystem token, the platform token includes the airdrop staking
+func holderQuery(ecosystem int64) *gorm.DB {
+	if ecosystem == 1 && AirdropReady {
+		return GetDB(nil).Table(`(SELECT ad.account,ad.total_amount+COALESCE(ai.stake_amount,0) AS total_amount,
+	ad.stake_amount+COALESCE(ai.stake_amount,0) AS stake_amount
+	FROM account_detail AS ad LEFT JOIN "1_airdrop_info" AS ai ON(ai.account = ad.account) WHERE ad.ecosystem = 1) AS v1`).
+			Where("total_amount > 0")
+	}
+	return GetDB(nil).Table("(SELECT account,total_amount,stake_amount FROM account_detail WHERE ecosystem = ?) AS v1", ecosystem).
+		Where("total_amount > 0")
+}
+
+func holderTokenInfo(ecosystem int64) (string, int) {
+	if ecosystem == 1 {
+		return SysTokenSymbol, EcoDigits.GetInt(1, 12)
+	}
+	return Tokens.Get(ecosystem), EcoDigits.GetInt(ecosystem, 0)
+}
+
+func getHolderRanks(ecosystem, day int64, accounts []string) (map[string]int64, error) {
+	type rank struct {
+		Account string
+		Rank    int64
+	}
+	var list []rank
+	err := GetDB(nil).Model(&HolderRankSnapshot{}).Select("account,rank").
+		Where("ecosystem = ? AND time = ? AND account IN ?", ecosystem, day, accounts).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	rets := make(map[string]int64, len(list))
+	for _, v := range list {
+		rets[v.Account] = v.Rank
+	}
+	return rets, nil
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// GetHolderRichList paginated holders of the ecosystem token by total amount
+func GetHolderRichList(ecosystem int64, page, limit int) (*GeneralResponse, error) {
+	var (
+		rets GeneralResponse
+		list []HolderRichList
+	)
+	rets.Page = page
+	rets.Limit = limit
+	if err := holderQuery(ecosystem).Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	err := holderQuery(ecosystem).Select("account,total_amount,stake_amount").
+		Order("total_amount desc,account asc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		rets.List = list
+		return &rets, nil
+	}
+
+	supply, _ := allKeyAmount.Get(ecosystem)
+	tokenSymbol, digits := holderTokenInfo(ecosystem)
+	accounts := make([]string, 0, len(list))
+	for _, v := range list {
+		accounts = append(accounts, v.Account)
+	}
+	today := dayStart(time.Now())
+	//the snapshot is taken at the first chart run of the day, the ranks of 24h ago are in the snapshot of yesterday
+	ranks1d, err := getHolderRanks(ecosystem, today.AddDate(0, 0, -1).Unix(), accounts)
+	if err != nil {
+		return nil, err
+	}
+	ranks7d, err := getHolderRanks(ecosystem, today.AddDate(0, 0, -7).Unix(), accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		list[i].Rank = int64((page-1)*limit + i + 1)
+		list[i].Iname = GetIName(list[i].Account)
+		list[i].Label = AddressLabels.Label(list[i].Account)
+		list[i].TokenSymbol = tokenSymbol
+		list[i].Digits = digits
+		if supply.GreaterThan(decimal.Zero) {
+			list[i].AccountedFor = list[i].TotalAmount.Mul(decimal.NewFromInt(100)).DivRound(supply, 4)
+		}
+		if r, ok := ranks1d[list[i].Account]; ok {
+			change := r - list[i].Rank
+			list[i].Change24h = &change
+		}
+		if r, ok := ranks7d[list[i].Account]; ok {
+			change := r - list[i].Rank
+			list[i].Change7d = &change
+		}
+	}
+	rets.List = list
+	return &rets, nil
+}
+
+// GetHolderDistribution calculates the distribution statistics of the ecosystem token
+func GetHolderDistribution(ecosystem int64) (*HolderDistribution, error) {
+	var rets HolderDistribution
+	rets.Ecosystem = ecosystem
+	rets.Time = time.Now().Unix()
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(ecosystem)
+
+	type giniSum struct {
+		Holders  int64
+		Total    decimal.Decimal
+		Weighted decimal.Decimal
+	}
+	var gs giniSum
+	//gini = 2*sum(i*x_i)/(n*sum(x)) - (n+1)/n, x sorted ascending
+	err := GetDB(nil).Table("(?) AS v2", holderQuery(ecosystem).
+		Select("total_amount,row_number() OVER (ORDER BY total_amount ASC) AS rn")).
+		Select("count(1) AS holders,coalesce(sum(total_amount),0) AS total,coalesce(sum(rn*total_amount),0) AS weighted").
+		Take(&gs).Error
+	if err != nil {
+		return nil, err
+	}
+	rets.Holders = gs.Holders
+	rets.TotalAmount = gs.Total
+	if gs.Holders == 0 || gs.Total.LessThanOrEqual(decimal.Zero) {
+		rets.Buckets = make([]HolderBucket, 0)
+		return &rets, nil
+	}
+	n := decimal.NewFromInt(gs.Holders)
+	rets.Gini = gs.Weighted.Mul(decimal.NewFromInt(2)).DivRound(n.Mul(gs.Total), 8).
+		Sub(n.Add(decimal.NewFromInt(1)).DivRound(n, 8)).Round(4)
+
+	for _, top := range []int{10, 100} {
+		var sum decimal.Decimal
+		err = GetDB(nil).Table("(?) AS v2", holderQuery(ecosystem).Select("total_amount").
+			Order("total_amount desc").Limit(top)).Select("coalesce(sum(total_amount),0)").Take(&sum).Error
+		if err != nil {
+			return nil, err
+		}
+		share := sum.Mul(decimal.NewFromInt(100)).DivRound(gs.Total, 2)
+		if top == 10 {
+			rets.Top10Share = share
+		} else {
+			rets.Top100Share = share
+		}
+	}
+
+	unit := decimal.New(1, int32(rets.Digits))
+	for i, min := range holderBuckets {
+		bk := HolderBucket{Min: min}
+		query := holderQuery(ecosystem).Where("total_amount >= ?", decimal.NewFromInt(min).Mul(unit))
+		if i+1 < len(holderBuckets) {
+			bk.Max = holderBuckets[i+1]
+			query = query.Where("total_amount < ?", decimal.NewFromInt(bk.Max).Mul(unit))
+		}
+		type bucketSum struct {
+			Holders int64
+			Amount  decimal.Decimal
+		}
+		var bs bucketSum
+		err = query.Select("count(1) AS holders,coalesce(sum(total_amount),0) AS amount").Take(&bs).Error
+		if err != nil {
+			return nil, err
+		}
+		bk.Holders = bs.Holders
+		bk.Amount = bs.Amount.String()
+		rets.Buckets = append(rets.Buckets, bk)
+	}
+	return &rets, nil
+}
+
+func insertHolderRankSnapshot(ecosystem, day int64) error {
+	type holder struct {
+		Account string
+	}
+	var list []holder
+	err := holderQuery(ecosystem).Select("account").Order("total_amount desc,account asc").
+		Limit(holderRankSnapshotTop).Find(&list).Error
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	snaps := make([]HolderRankSnapshot, 0, len(list))
+	for i, v := range list {
+		snaps = append(snaps, HolderRankSnapshot{Ecosystem: ecosystem, Time: day, Account: v.Account, Rank: int64(i + 1)})
+	}
+	return GetDB(nil).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ecosystem = ? AND time = ?", ecosystem, day).Delete(&HolderRankSnapshot{}).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(&snaps, 1000).Error
+	})
+}
+
+func insertHolderDistributionReport(ecosystem, day int64) error {
+	var rt HolderDistributionReport
+	f, err := isFound(GetDB(nil).Where("ecosystem = ? AND time = ?", ecosystem, day).Take(&rt))
+	if err != nil || f {
+		return err
+	}
+	info, err := GetHolderDistribution(ecosystem)
+	if err != nil {
+		return err
+	}
+	if info.Holders == 0 {
+		return nil
+	}
+	buckets, _ := json.Marshal(info.Buckets)
+	rt = HolderDistributionReport{
+		Ecosystem:   ecosystem,
+		Time:        day,
+		Holders:     info.Holders,
+		TotalAmount: info.TotalAmount.String(),
+		Top10Share:  info.Top10Share.String(),
+		Top100Share: info.Top100Share.String(),
+		Gini:        info.Gini.String(),
+		Buckets:     string(buckets),
+	}
+	return GetDB(nil).Create(&rt).Error
+}
+
+// InsertHolderDistributionReport records the daily distribution and the rank of top holders, run by the chart crontab
+func InsertHolderDistributionReport() {
+	ChartWG.Add(1)
+	defer func() {
+		ChartWG.Done()
+	}()
+	if !AccountDetailTableExist() {
+		return
+	}
+	day := dayStart(time.Now()).Unix()
+	for _, ecosystem := range EcosystemIdList {
+		if err := insertHolderDistributionReport(ecosystem, day); err != nil {
+			log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("insert holder distribution report failed")
+		}
+		//the snapshot is checked by its own rows, a failed snapshot is retried by the next run
+		var ranks int64
+		err := GetDB(nil).Model(&HolderRankSnapshot{}).Where("ecosystem = ? AND time = ?", ecosystem, day).Count(&ranks).Error
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("get holder rank snapshot failed")
+			continue
+		}
+		if ranks > 0 {
+			continue
+		}
+		if err = insertHolderRankSnapshot(ecosystem, day); err != nil {
+			log.WithFields(log.Fields{"error": err, "ecosystem": ecosystem}).Error("insert holder rank snapshot failed")
+		}
+	}
+	expired := time.Unix(day, 0).AddDate(0, 0, -holderRankSnapshotDays).Unix()
+	if err := GetDB(nil).Where("time < ?", expired).Delete(&HolderRankSnapshot{}).Error; err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("delete expired holder rank snapshot failed")
+	}
+}
+
+// GetHolderDistributionChart daily time series of the distribution statistics
+func GetHolderDistributionChart(ecosystem, startTime, endTime int64) (*HolderDistributionChart, error) {
+	if endTime > 0 && startTime > endTime {
+		return nil, errors.New("request params invalid")
+	}
+	var (
+		rets HolderDistributionChart
+		list []HolderDistributionReport
+	)
+	rets.Ecosystem = ecosystem
+	query := GetDB(nil).Where("ecosystem = ?", ecosystem)
+	if startTime > 0 {
+		query = query.Where("time >= ?", startTime)
+	}
+	if endTime > 0 {
+		query = query.Where("time < ?", time.Unix(endTime, 0).AddDate(0, 0, 1).Unix())
+	}
+	if err := query.Order("time asc").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		rets.Time = append(rets.Time, v.Time)
+		rets.Holders = append(rets.Holders, v.Holders)
+		rets.Top10Share = append(rets.Top10Share, v.Top10Share)
+		rets.Top100Share = append(rets.Top100Share, v.Top100Share)
+		rets.Gini = append(rets.Gini, v.Gini)
+	}
+	return &rets, nil
+}
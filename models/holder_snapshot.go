@@ -0,0 +1,347 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	SnapshotPending = iota
+	SnapshotRunning
+	SnapshotDone
+	SnapshotFailed
+)
+
+const snapshotQueueSize = 100
+
+// HolderSnapshot holder balances of an ecosystem token at a block.
+// The balance is the account balance in 1_history plus the utxo balance in spent_info_history
+type HolderSnapshot struct {
+	ID          int64  `gorm:"primary_key;not null" json:"id"`
+	Ecosystem   int64  `gorm:"not null;index:idx_holder_snapshot_eco_block" json:"ecosystem"`
+	Block       int64  `gorm:"not null;index:idx_holder_snapshot_eco_block" json:"block"`
+	Status      int    `gorm:"not null" json:"status"` //0:pending 1:running 2:done 3:failed
+	Holders     int64  `gorm:"not null" json:"holders"`
+	TotalAmount string `gorm:"type:decimal(30);not null;default:0" json:"total_amount"`
+	ContentHash string `gorm:"not null" json:"content_hash"` //sha256 of the csv export, the X-Content-Hash of every download format
+	Error       string `gorm:"not null" json:"error"`
+	CreatedAt   int64  `gorm:"not null" json:"created_at"`
+	FinishedAt  int64  `gorm:"not null" json:"finished_at"`
+}
+
+type HolderSnapshotItem struct {
+	ID         int64           `gorm:"primary_key;not null" json:"-"`
+	SnapshotId int64           `gorm:"not null;index" json:"-"`
+	Account    string          `gorm:"not null" json:"account"`
+	Balance    decimal.Decimal `gorm:"type:decimal(30);not null" json:"balance"`
+}
+
+type HolderSnapshotExport struct {
+	HolderSnapshot
+	TokenSymbol string               `json:"token_symbol"`
+	Digits      int                  `json:"digits"`
+	List        []HolderSnapshotItem `json:"list"`
+}
+
+type HolderSnapshotDiff struct {
+	Account     string          `json:"account"`
+	FromBalance decimal.Decimal `json:"from_balance"`
+	ToBalance   decimal.Decimal `json:"to_balance"`
+	Change      decimal.Decimal `json:"change"`
+}
+
+var snapshotQueue chan int64
+
+func (p *HolderSnapshot) TableName() string {
+	return "holder_snapshot"
+}
+
+func (p *HolderSnapshot) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (p *HolderSnapshot) GetById(id int64) (bool, error) {
+	return isFound(GetDB(nil).Where("id = ?", id).Take(p))
+}
+
+func (p *HolderSnapshotItem) TableName() string {
+	return "holder_snapshot_item"
+}
+
+func (p *HolderSnapshotItem) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// InitHolderSnapshot creates the tables and starts the worker, unfinished jobs are queued again
+func InitHolderSnapshot() error {
+	var (
+		p  HolderSnapshot
+		it HolderSnapshotItem
+	)
+	if err := p.CreateTable(); err != nil {
+		return err
+	}
+	if err := it.CreateTable(); err != nil {
+		return err
+	}
+	snapshotQueue = make(chan int64, snapshotQueueSize)
+	go holderSnapshotWorker()
+
+	var ids []int64
+	err := GetDB(nil).Model(&p).Where("status IN ?", []int{SnapshotPending, SnapshotRunning}).Order("id asc").Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		select {
+		case snapshotQueue <- id:
+		default:
+		}
+	}
+	return nil
+}
+
+func holderSnapshotWorker() {
+	for id := range snapshotQueue {
+		if err := runHolderSnapshot(id); err != nil {
+			log.WithFields(log.Fields{"error": err, "snapshot": id}).Error("holder snapshot failed")
+			GetDB(nil).Model(&HolderSnapshot{}).Where("id = ?", id).Updates(map[string]any{
+				"status": SnapshotFailed, "error": err.Error(), "finished_at": time.Now().Unix(),
+			})
+		}
+	}
+}
+
+// CreateHolderSnapshot queues a snapshot job, the finished snapshot of the same ecosystem and block is reused
+func CreateHolderSnapshot(ecosystem, block int64) (*HolderSnapshot, error) {
+	if snapshotQueue == nil {
+		return nil, errors.New("holder snapshot service doesn't not ready")
+	}
+	var bk Block
+	f, err := bk.GetMaxBlock()
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("block doesn't not exist")
+	}
+	if block <= 0 {
+		block = bk.ID
+	}
+	if block > bk.ID {
+		return nil, fmt.Errorf("block %d is greater than the max block %d", block, bk.ID)
+	}
+	if Info.Get(ecosystem).Id != ecosystem {
+		return nil, errors.New("ecosystem doesn't not exist")
+	}
+
+	var p HolderSnapshot
+	f, err = isFound(GetDB(nil).Where("ecosystem = ? AND block = ? AND status <> ?", ecosystem, block, SnapshotFailed).
+		Order("id desc").Take(&p))
+	if err != nil {
+		return nil, err
+	}
+	if f {
+		return &p, nil
+	}
+	p = HolderSnapshot{Ecosystem: ecosystem, Block: block, Status: SnapshotPending, TotalAmount: "0", CreatedAt: time.Now().Unix()}
+	if err = GetDB(nil).Create(&p).Error; err != nil {
+		return nil, err
+	}
+	select {
+	case snapshotQueue <- p.ID:
+	default:
+		GetDB(nil).Model(&p).Updates(map[string]any{"status": SnapshotFailed, "error": "snapshot queue is full"})
+		return nil, errors.New("snapshot queue is full, please try again later")
+	}
+	return &p, nil
+}
+
+func runHolderSnapshot(id int64) error {
+	var p HolderSnapshot
+	f, err := p.GetById(id)
+	if err != nil {
+		return err
+	}
+	if !f || p.Status == SnapshotDone {
+		return nil
+	}
+	if err = GetDB(nil).Model(&p).Update("status", SnapshotRunning).Error; err != nil {
+		return err
+	}
+
+	type keyBalance struct {
+		KeyId   int64
+		Balance decimal.Decimal
+	}
+	var list []keyBalance
+	err = GetDB(nil).Raw(`
+WITH h AS(
+	SELECT DISTINCT ON (key_id) key_id,balance FROM(
+		SELECT sender_id AS key_id,sender_balance AS balance,id FROM "1_history" WHERE ecosystem = ? AND block_id <= ? AND sender_id <> 0
+		UNION ALL
+		SELECT recipient_id AS key_id,recipient_balance AS balance,id FROM "1_history" WHERE ecosystem = ? AND block_id <= ? AND recipient_id <> 0
+	)AS v1 ORDER BY key_id,id DESC
+),u AS(
+	SELECT DISTINCT ON (key_id) key_id,balance FROM(
+		SELECT sender_id AS key_id,sender_balance AS balance,id FROM spent_info_history WHERE ecosystem = ? AND block <= ? AND sender_id <> 0
+		UNION ALL
+		SELECT recipient_id AS key_id,recipient_balance AS balance,id FROM spent_info_history WHERE ecosystem = ? AND block <= ? AND recipient_id <> 0
+	)AS v2 ORDER BY key_id,id DESC
+)
+SELECT * FROM(
+	SELECT COALESCE(h.key_id,u.key_id) AS key_id,COALESCE(h.balance,0)+COALESCE(u.balance,0) AS balance
+	FROM h FULL JOIN u ON(u.key_id = h.key_id)
+)AS v3 WHERE balance > 0 ORDER BY balance DESC,key_id ASC
+`, p.Ecosystem, p.Block, p.Ecosystem, p.Block, p.Ecosystem, p.Block, p.Ecosystem, p.Block).Find(&list).Error
+	if err != nil {
+		return err
+	}
+
+	items := make([]HolderSnapshotItem, 0, len(list))
+	total := decimal.Zero
+	for _, v := range list {
+		items = append(items, HolderSnapshotItem{SnapshotId: p.ID, Account: converter.AddressToString(v.KeyId), Balance: v.Balance})
+		total = total.Add(v.Balance)
+	}
+	return GetDB(nil).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("snapshot_id = ?", p.ID).Delete(&HolderSnapshotItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			if err := tx.CreateInBatches(&items, 1000).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&p).Updates(map[string]any{
+			"status":       SnapshotDone,
+			"holders":      len(items),
+			"total_amount": total,
+			"content_hash": holderSnapshotHash(items),
+			"error":        "",
+			"finished_at":  time.Now().Unix(),
+		}).Error
+	})
+}
+
+// HolderSnapshotCSV the csv export, ordered by balance desc and account asc
+func HolderSnapshotCSV(items []HolderSnapshotItem) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("account,balance\n")
+	for _, v := range items {
+		buf.WriteString(v.Account)
+		buf.WriteByte(',')
+		buf.WriteString(v.Balance.String())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// holderSnapshotHash the sha256 hex of the csv export, it identifies the snapshot content in every download format
+func holderSnapshotHash(items []HolderSnapshotItem) string {
+	sum := sha256.Sum256(HolderSnapshotCSV(items))
+	return hex.EncodeToString(sum[:])
+}
+
+func getDoneHolderSnapshot(id int64) (*HolderSnapshot, error) {
+	var p HolderSnapshot
+	f, err := p.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("snapshot doesn't not exist")
+	}
+	if p.Status != SnapshotDone {
+		return nil, fmt.Errorf("snapshot %d doesn't not finished", id)
+	}
+	return &p, nil
+}
+
+func GetHolderSnapshot(id int64) (*HolderSnapshot, error) {
+	var p HolderSnapshot
+	f, err := p.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("snapshot doesn't not exist")
+	}
+	return &p, nil
+}
+
+func GetHolderSnapshotExport(id int64) (*HolderSnapshotExport, error) {
+	p, err := getDoneHolderSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	var rets HolderSnapshotExport
+	rets.HolderSnapshot = *p
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(p.Ecosystem)
+	err = GetDB(nil).Where("snapshot_id = ?", id).Order("id asc").Find(&rets.List).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rets, nil
+}
+
+// GetHolderSnapshotDiff the accounts whose balance changed between two snapshots of the same ecosystem
+func GetHolderSnapshotDiff(fromId, toId int64, page, limit int) (*GeneralResponse, error) {
+	from, err := getDoneHolderSnapshot(fromId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getDoneHolderSnapshot(toId)
+	if err != nil {
+		return nil, err
+	}
+	if from.Ecosystem != to.Ecosystem {
+		return nil, errors.New("snapshots are not of the same ecosystem")
+	}
+	var (
+		rets GeneralResponse
+		list []HolderSnapshotDiff
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Table(`(
+	SELECT COALESCE(f.account,t.account) AS account,COALESCE(f.balance,0) AS from_balance,COALESCE(t.balance,0) AS to_balance,
+		COALESCE(t.balance,0)-COALESCE(f.balance,0) AS change
+	FROM (SELECT account,balance FROM holder_snapshot_item WHERE snapshot_id = ?) AS f
+	FULL JOIN (SELECT account,balance FROM holder_snapshot_item WHERE snapshot_id = ?) AS t ON(t.account = f.account)
+)AS v1`, fromId, toId).Where("change <> 0")
+	if err = query.Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	if err = query.Order("abs(change) desc,account asc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	rets.List = list
+	return &rets, nil
+}
@@ -5,6 +5,14 @@
 
 package models
 
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
 type IName struct {
 	Id          int64 `gorm:"primary_key;not_null"`
 	Account     string
@@ -31,3 +39,143 @@ func INameTableExist() bool {
 func (p *IName) Get(account string) (bool, error) {
 	return isFound(GetDB(nil).Where("account = ? AND ecosystem = 1", account).Take(p))
 }
+
+type INameResponse struct {
+	Account string `json:"account"`
+	Name    string `json:"name"`
+}
+
+// inameCache forward (name -> account) and reverse (account -> name) resolution of platform inames,
+// missing holds the accounts without iname that were queried since the last refresh
+type inameCache struct {
+	byAccount map[string]string
+	byName    map[string]string
+	missing   map[string]bool
+	sync.RWMutex
+}
+
+var INames = &inameCache{byAccount: make(map[string]string), byName: make(map[string]string), missing: make(map[string]bool)}
+
+func (p *IName) GetByName(name string) (bool, error) {
+	return isFound(GetDB(nil).Where("lower(name) = lower(?) AND ecosystem = 1", name).Take(p))
+}
+
+// RefreshINameCache reloads all platform inames, it is refreshed with the ecosystem info
+func RefreshINameCache() {
+	if !INameReady {
+		return
+	}
+	var list []IName
+	err := GetDB(nil).Select("account,name").Where("ecosystem = 1").Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("refresh iname cache failed")
+		return
+	}
+	byAccount := make(map[string]string, len(list))
+	byName := make(map[string]string, len(list))
+	for _, v := range list {
+		byAccount[v.Account] = v.Name
+		byName[strings.ToLower(v.Name)] = v.Account
+	}
+	INames.Lock()
+	defer INames.Unlock()
+	INames.byAccount = byAccount
+	INames.byName = byName
+	INames.missing = make(map[string]bool)
+}
+
+// Name reverse resolution, empty if the account has no iname
+func (c *inameCache) Name(account string) string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.byAccount[account]
+}
+
+// Account forward resolution
+func (c *inameCache) Account(name string) (string, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	account, ok := c.byName[strings.ToLower(name)]
+	return account, ok
+}
+
+func (c *inameCache) List() []INameResponse {
+	c.RLock()
+	defer c.RUnlock()
+	list := make([]INameResponse, 0, len(c.byAccount))
+	for account, name := range c.byAccount {
+		list = append(list, INameResponse{Account: account, Name: name})
+	}
+	return list
+}
+
+// lookup the cached iname of the account, known is false when the account was not queried since the last refresh
+func (c *inameCache) lookup(account string) (name string, known bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if name, ok := c.byAccount[account]; ok {
+		return name, true
+	}
+	return "", c.missing[account]
+}
+
+// store caches the iname of the account, an empty name caches the account as missing until the next refresh
+func (c *inameCache) store(account, name string) {
+	c.Lock()
+	defer c.Unlock()
+	if name == "" {
+		c.missing[account] = true
+		return
+	}
+	c.byAccount[account] = name
+	c.byName[strings.ToLower(name)] = account
+}
+
+// GetIName returns the iname of the account, the database is queried when the cache missed
+// and the result, found or not, is cached until the next refresh
+func GetIName(account string) string {
+	if !INameReady || account == "" {
+		return ""
+	}
+	if name, known := INames.lookup(account); known {
+		return name
+	}
+	ie := &IName{}
+	f, err := ie.Get(account)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "account": account}).Warn("get iname failed")
+		return ""
+	}
+	if !f {
+		ie.Name = ""
+	}
+	INames.store(account, ie.Name)
+	return ie.Name
+}
+
+// ResolveIName resolves an account or an iname, the database is queried when the cache missed
+func ResolveIName(value string) (*INameResponse, error) {
+	if !INameReady {
+		return nil, errors.New("iname doesn't not exist")
+	}
+	value = strings.TrimSpace(value)
+	if info := ValidateAddress(value); info.Valid {
+		name := GetIName(info.Address)
+		if name == "" {
+			return nil, errors.New("iname doesn't not exist")
+		}
+		return &INameResponse{Account: info.Address, Name: name}, nil
+	}
+	if account, ok := INames.Account(value); ok {
+		return &INameResponse{Account: account, Name: INames.Name(account)}, nil
+	}
+	ie := &IName{}
+	f, err := ie.GetByName(value)
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("iname doesn't not exist")
+	}
+	return &INameResponse{Account: ie.Account, Name: ie.Name}, nil
+}
@@ -0,0 +1,26 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import "testing"
+
+func TestINameCache(t *testing.T) {
+	c := &inameCache{byAccount: make(map[string]string), byName: make(map[string]string), missing: make(map[string]bool)}
+	if _, known := c.lookup("0001"); known {
+		t.Fatal("an account not queried must not be known")
+	}
+	c.store("0001", "")
+	if name, known := c.lookup("0001"); !known || name != "" {
+		t.Fatalf("missing account = %q known %v, want cached as missing", name, known)
+	}
+	c.store("0002", "Alice")
+	if name, known := c.lookup("0002"); !known || name != "Alice" {
+		t.Fatalf("iname = %q known %v", name, known)
+	}
+	if account, ok := c.Account("alice"); !ok || account != "0002" {
+		t.Fatalf("forward resolution = %q %v", account, ok)
+	}
+}
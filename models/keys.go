@@ -65,31 +65,33 @@ type EcosyKeyHex struct {
 }
 
 type EcosyKeyTotalHex struct {
-	Wallet         string          `json:"wallet"`
-	Ecosystem      int64           `json:"ecosystem"`
-	IsValued       int64           `json:"isvalued"`
-	Ecosystemname  string          `json:"ecosystemname"`
-	TokenSymbol    string          `json:"token_symbol"`
-	Amount         string          `json:"amount"`
-	Info           string          `json:"info"`
-	EmissionAmount string          `json:"emission_amount"`
-	MemberName     string          `json:"member_name"`
-	MemberHash     string          `json:"member_hash"`
-	LogoHash       string          `json:"logo_hash"`
-	TypeEmission   int64           `json:"type_emission"`
-	TypeWithdraw   int64           `json:"type_withdraw"`
-	Transaction    int64           `json:"transaction"`
-	InTx           int64           `json:"in_tx"`
-	OutTx          int64           `json:"out_tx"`
-	StakeAmount    decimal.Decimal `json:"stake_amount"`
-	LockAmount     decimal.Decimal `json:"lock_amount"`
-	InAmount       decimal.Decimal `json:"inamount"`
-	OutAmount      decimal.Decimal `json:"outamount"`
-	TxAmount       string          `json:"tx_amount"`
-	TotalAmount    decimal.Decimal `json:"total_amount"`
-	JoinTime       int64           `json:"join_time"`
-	RolesName      string          `json:"roles_name"`
-	Digits         int             `json:"digits"`
+	Wallet         string            `json:"wallet"`
+	Ecosystem      int64             `json:"ecosystem"`
+	IsValued       int64             `json:"isvalued"`
+	Ecosystemname  string            `json:"ecosystemname"`
+	TokenSymbol    string            `json:"token_symbol"`
+	Amount         string            `json:"amount"`
+	Info           string            `json:"info"`
+	EmissionAmount string            `json:"emission_amount"`
+	MemberName     string            `json:"member_name"`
+	MemberHash     string            `json:"member_hash"`
+	Iname          string            `json:"iname,omitempty"`
+	Label          *AddressLabelInfo `json:"label,omitempty" gorm:"-"`
+	LogoHash       string            `json:"logo_hash"`
+	TypeEmission   int64             `json:"type_emission"`
+	TypeWithdraw   int64             `json:"type_withdraw"`
+	Transaction    int64             `json:"transaction"`
+	InTx           int64             `json:"in_tx"`
+	OutTx          int64             `json:"out_tx"`
+	StakeAmount    decimal.Decimal   `json:"stake_amount"`
+	LockAmount     decimal.Decimal   `json:"lock_amount"`
+	InAmount       decimal.Decimal   `json:"inamount"`
+	OutAmount      decimal.Decimal   `json:"outamount"`
+	TxAmount       string            `json:"tx_amount"`
+	TotalAmount    decimal.Decimal   `json:"total_amount"`
+	JoinTime       int64             `json:"join_time"`
+	RolesName      string            `json:"roles_name"`
+	Digits         int               `json:"digits"`
 }
 
 type EcosyKeyTotalDetail struct {
@@ -123,16 +125,17 @@ type KeysResult struct {
 }
 
 type EcosyKeyList struct {
-	Ecosystem    int64           `json:"ecosystem"`
-	Account      string          `json:"account"`
-	AccountName  string          `json:"account_name"`
-	Amount       string          `json:"amount"`
-	TotalAmount  decimal.Decimal `json:"total_amount"`
-	AccountedFor decimal.Decimal `json:"accounted_for"`
-	StakeAmount  decimal.Decimal `json:"stake_amount"`
-	LockAmount   decimal.Decimal `json:"lock_amount"`
-	TokenSymbol  string          `json:"token_symbol"`
-	Digits       int             `json:"digits"`
+	Ecosystem    int64             `json:"ecosystem"`
+	Account      string            `json:"account"`
+	AccountName  string            `json:"account_name"`
+	Label        *AddressLabelInfo `json:"label,omitempty" gorm:"-"`
+	Amount       string            `json:"amount"`
+	TotalAmount  decimal.Decimal   `json:"total_amount"`
+	AccountedFor decimal.Decimal   `json:"accounted_for"`
+	StakeAmount  decimal.Decimal   `json:"stake_amount"`
+	LockAmount   decimal.Decimal   `json:"lock_amount"`
+	TokenSymbol  string            `json:"token_symbol"`
+	Digits       int               `json:"digits"`
 }
 
 type KeysListResult struct {
@@ -280,12 +283,8 @@ func (m *Key) GetKeyDetail(keyId int64, wallet string) (*EcosyKeyTotalHex, error
 			}
 		}
 	}
-	if INameReady {
-		ie := &IName{}
-		f, err := ie.Get(wallet)
-		if err == nil && f {
-			d.MemberName = ie.Name
-		}
+	if name := GetIName(wallet); name != "" {
+		d.MemberName = name
 	}
 
 	//
@@ -454,6 +453,8 @@ func (m *Key) GetWalletTotalBasisEcosystem(wallet string) (*EcosyKeyTotalHex, er
 			return &ret, err
 		}
 		ret = *df
+		ret.Iname = GetIName(converter.AddressToString(wid))
+		ret.Label = AddressLabels.Label(converter.AddressToString(wid))
 	} else {
 		return &ret, errors.New("wallet invalid")
 	}
@@ -659,13 +660,8 @@ func (m *Key) GetAccountList(page, limit int, ecosystem int64) (*KeysListResult,
 		if amount.GreaterThan(decimal.Zero) {
 			ret.Rets[i].AccountedFor = amount.Mul(decimal.NewFromInt(100)).DivRound(totalAmount, 2)
 		}
-		if INameReady {
-			in := &IName{}
-			f, err := in.Get(ret.Rets[i].Account)
-			if err == nil && f {
-				ret.Rets[i].AccountName = in.Name
-			}
-		}
+		ret.Rets[i].AccountName = GetIName(ret.Rets[i].Account)
+		ret.Rets[i].Label = AddressLabels.Label(ret.Rets[i].Account)
 	}
 
 	return &ret, nil
@@ -808,13 +804,8 @@ order by
 				ret[i].Committee = true
 			}
 		}
-		if INameReady {
-			ie := &IName{}
-			f, err := ie.Get(ret[i].Account)
-			if err == nil && f {
-				ret[i].AccountName = ie.Name
-			}
-		}
+		ret[i].AccountName = GetIName(ret[i].Account)
+		ret[i].Label = AddressLabels.Label(ret[i].Account)
 		ret[i].TokenSymbol = tokenSymbol
 		ret[i].Digits = digits
 		ret[i].AccountedFor = ret[i].Amount.Mul(decimal.NewFromInt(100)).DivRound(ecoTotal, 2)
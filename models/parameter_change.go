@@ -0,0 +1,309 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	platformParameterTable  = "1_platform_parameters"
+	ecosystemParameterTable = "1_parameters"
+
+	parameterChangeBatch = 1000
+)
+
+var parameterChangeLock sync.Mutex
+
+// ParameterChange a change of 1_platform_parameters(ecosystem 0) or 1_parameters, recorded from rollback_tx.
+// The old value is the rollback data of the change, the new value is the old value of the next change or the current value
+type ParameterChange struct {
+	ID           int64  `gorm:"primary_key;not null" json:"id"`
+	RollbackId   int64  `gorm:"not null;uniqueIndex" json:"-"`
+	Ecosystem    int64  `gorm:"not null;index:idx_parameter_change_name" json:"ecosystem"`
+	ParamId      int64  `gorm:"not null;index" json:"param_id"`
+	Name         string `gorm:"not null;index:idx_parameter_change_name" json:"name"`
+	OldValue     string `gorm:"not null;type:text" json:"old_value"`
+	NewValue     string `gorm:"not null;type:text" json:"new_value"`
+	Block        int64  `gorm:"not null" json:"block"`
+	Hash         string `gorm:"not null" json:"hash"`
+	Time         int64  `gorm:"not null" json:"time"`
+	ContractName string `gorm:"not null" json:"contract_name"`
+	VotingId     int64  `gorm:"not null" json:"voting_id"` //the dao voting that enacted the change, 0 if none
+}
+
+type FuelRateChartResponse struct {
+	Ecosystem int64    `json:"ecosystem"`
+	Time      []int64  `json:"time"`
+	Block     []int64  `json:"block"`
+	FuelRate  []string `json:"fuel_rate"`
+}
+
+func (p *ParameterChange) TableName() string {
+	return "parameter_change"
+}
+
+func (p *ParameterChange) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func InitParameterChange() error {
+	var p ParameterChange
+	return p.CreateTable()
+}
+
+type parameterRollback struct {
+	Id           int64
+	BlockId      int64
+	TxHash       []byte
+	TableName    string
+	ParamId      int64
+	Data         string
+	Time         int64
+	ContractName string
+}
+
+type currentParameter struct {
+	Ecosystem int64
+	Name      string
+	Value     string
+}
+
+// SyncParameterChange records the parameter changes of the new rollback_tx rows
+func SyncParameterChange() {
+	RealtimeWG.Add(1)
+	defer func() {
+		RealtimeWG.Done()
+	}()
+	if !parameterChangeLock.TryLock() {
+		return
+	}
+	defer parameterChangeLock.Unlock()
+
+	var (
+		p    ParameterChange
+		last int64
+		list []parameterRollback
+	)
+	if !HasTableOrView(p.TableName()) {
+		return
+	}
+	err := GetDB(nil).Model(&p).Select("COALESCE(max(rollback_id),0)").Take(&last).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("get parameter change last rollback id failed")
+		return
+	}
+	err = GetDB(nil).Raw(`
+SELECT rt.id,rt.block_id,rt.tx_hash,rt.table_name,
+	CAST(COALESCE(NULLIF(SPLIT_PART(rt.table_id,',',1),''),'0') AS BIGINT) AS param_id,rt.data,
+	COALESCE(bk.time,0) AS time,COALESCE(lt.contract_name,'') AS contract_name
+FROM rollback_tx AS rt
+LEFT JOIN block_chain AS bk ON(bk.id = rt.block_id)
+LEFT JOIN log_transactions AS lt ON(lt.hash = rt.tx_hash)
+WHERE rt.table_name IN(?,?) AND rt.id > ? ORDER BY rt.id ASC LIMIT ?
+`, platformParameterTable, ecosystemParameterTable, last, parameterChangeBatch).Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("get parameter rollback list failed")
+		return
+	}
+
+	current := make(map[string]*currentParameter)
+	for _, v := range list {
+		key := v.TableName + "," + strconv.FormatInt(v.ParamId, 10)
+		cur, ok := current[key]
+		if !ok {
+			cur, err = getCurrentParameter(v.TableName, v.ParamId)
+			if err != nil {
+				log.WithFields(log.Fields{"error": err, "table": v.TableName, "id": v.ParamId}).Error("get current parameter failed")
+				return
+			}
+			current[key] = cur
+		}
+		if cur == nil {
+			continue
+		}
+		var oldValue string
+		if v.Data != "" {
+			var data map[string]any
+			if err = json.Unmarshal([]byte(v.Data), &data); err != nil {
+				continue
+			}
+			val, ok := data["value"]
+			if !ok {
+				//only the conditions changed
+				continue
+			}
+			oldValue = parameterValueString(val)
+		}
+		if err = insertParameterChange(v, cur, oldValue); err != nil {
+			log.WithFields(log.Fields{"error": err, "rollback": v.Id}).Error("insert parameter change failed")
+			return
+		}
+	}
+}
+
+func parameterValueString(val any) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+func getCurrentParameter(table string, id int64) (*currentParameter, error) {
+	var cur currentParameter
+	query := GetDB(nil).Table(table).Where("id = ?", id)
+	if table == platformParameterTable {
+		query = query.Select("0 AS ecosystem,name,value")
+	} else {
+		query = query.Select("ecosystem,name,value")
+	}
+	f, err := isFound(query.Take(&cur))
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, nil
+	}
+	return &cur, nil
+}
+
+func insertParameterChange(v parameterRollback, cur *currentParameter, oldValue string) error {
+	var prev ParameterChange
+	f, err := isFound(GetDB(nil).Where("ecosystem = ? AND param_id = ? AND name = ?", cur.Ecosystem, v.ParamId, cur.Name).
+		Order("rollback_id desc").Take(&prev))
+	if err != nil {
+		return err
+	}
+	if f && prev.NewValue != oldValue {
+		prev.NewValue = oldValue
+		prev.VotingId = getParameterVoting(prev.Ecosystem, prev.ContractName, prev.NewValue, prev.Time)
+		err = GetDB(nil).Model(&prev).Updates(map[string]any{"new_value": prev.NewValue, "voting_id": prev.VotingId}).Error
+		if err != nil {
+			return err
+		}
+	}
+	item := ParameterChange{
+		RollbackId:   v.Id,
+		Ecosystem:    cur.Ecosystem,
+		ParamId:      v.ParamId,
+		Name:         cur.Name,
+		OldValue:     oldValue,
+		NewValue:     cur.Value,
+		Block:        v.BlockId,
+		Hash:         hex.EncodeToString(v.TxHash),
+		Time:         v.Time,
+		ContractName: v.ContractName,
+	}
+	item.VotingId = getParameterVoting(item.Ecosystem, item.ContractName, item.NewValue, item.Time)
+	return GetDB(nil).Create(&item).Error
+}
+
+// getParameterVoting the latest finished voting whose accept params carry the new value, only for changes made by voting contracts
+func getParameterVoting(ecosystem int64, contractName, value string, time int64) int64 {
+	if !VotingReady || !strings.Contains(strings.ToLower(contractName), "voting") {
+		return 0
+	}
+	if ecosystem == 0 {
+		ecosystem = 1
+	}
+	var votingId int64
+	f, err := isFound(GetDB(nil).Table(`"1_votings_subject" AS vs`).Select("vs.voting_id").
+		Joins(`LEFT JOIN "1_votings" AS v ON(v.id = vs.voting_id)`).
+		Where("vs.ecosystem = ? AND vs.subject->'contract_accept_params'->>'Value' = ? AND v.date_ended <= ?", ecosystem, value, time).
+		Order("v.date_ended desc").Limit(1).Take(&votingId))
+	if err != nil || !f {
+		return 0
+	}
+	return votingId
+}
+
+// GetParameterTimeline the parameter changes of an ecosystem, ecosystem 0 is the platform parameters
+func GetParameterTimeline(ecosystem int64, name string, page, limit int) (*GeneralResponse, error) {
+	var (
+		rets GeneralResponse
+		list []ParameterChange
+	)
+	rets.Page = page
+	rets.Limit = limit
+	query := GetDB(nil).Model(&ParameterChange{}).Where("ecosystem = ?", ecosystem)
+	if name != "" {
+		query = query.Where("name like ?", "%"+name+"%")
+	}
+	if err := query.Count(&rets.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Order("rollback_id desc").Offset((page - 1) * limit).Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	rets.List = list
+	return &rets, nil
+}
+
+// GetParameterHistory all changes of one parameter, from old to new
+func GetParameterHistory(ecosystem int64, name string) ([]ParameterChange, error) {
+	var list []ParameterChange
+	err := GetDB(nil).Where("ecosystem = ? AND name = ?", ecosystem, name).Order("rollback_id asc").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func parseFuelRate(value string, ecosystem int64) (string, bool) {
+	var values [][]string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return "", false
+	}
+	for _, v := range values {
+		if len(v) == 2 {
+			eco, _ := strconv.ParseInt(v[0], 10, 64)
+			if eco == ecosystem {
+				return v[1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// GetFuelRateChart the fuel rate of the ecosystem after each fuel_rate change
+func GetFuelRateChart(ecosystem int64) (*FuelRateChartResponse, error) {
+	var rets FuelRateChartResponse
+	rets.Ecosystem = ecosystem
+	list, err := GetParameterHistory(0, "fuel_rate")
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		rate, ok := parseFuelRate(v.NewValue, ecosystem)
+		if !ok {
+			continue
+		}
+		if n := len(rets.FuelRate); n > 0 && rets.FuelRate[n-1] == rate {
+			continue
+		}
+		rets.Time = append(rets.Time, v.Time)
+		rets.Block = append(rets.Block, v.Block)
+		rets.FuelRate = append(rets.FuelRate, rate)
+	}
+	return &rets, nil
+}
@@ -0,0 +1,462 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	PriceSourceDex    = "dex"
+	PriceSourceHttp   = "http"
+	PriceSourceStatic = "static"
+
+	DefaultPriceCurrency = "USD"
+
+	httpPriceRefresh = 60
+	httpPriceTimeout = 10 * time.Second
+)
+
+// PriceSource provides the prices of the ecosystem tokens
+type PriceSource interface {
+	Name() string
+	Currency() string
+	// MaxAge seconds after which a price of the source is stale, 0 never stale
+	MaxAge() int64
+	// GetPrices the prices of the ecosystems that the source knows, the others are not returned
+	GetPrices(ecosystems []int64) (map[int64]SourcePrice, error)
+}
+
+type SourcePrice struct {
+	Price     decimal.Decimal
+	UpdatedAt int64
+}
+
+type OraclePrice struct {
+	Ecosystem int64  `json:"ecosystem"`
+	Price     string `json:"price"`
+	Currency  string `json:"currency"`
+	Source    string `json:"source"`
+	UpdatedAt int64  `json:"updated_at"`
+	Stale     bool   `json:"stale"`
+}
+
+var (
+	priceSources     []PriceSource
+	priceSourcesOnce sync.Once
+)
+
+func getPriceSources() []PriceSource {
+	priceSourcesOnce.Do(func() {
+		cfg := conf.GetEnvConf().Price
+		for _, v := range cfg.Sources {
+			currency := strings.ToUpper(v.Currency)
+			if currency == "" {
+				currency = DefaultPriceCurrency
+			}
+			name := v.Name
+			if name == "" {
+				name = v.Type
+			}
+			switch v.Type {
+			case PriceSourceDex:
+				priceSources = append(priceSources, &dexPriceSource{name: name, currency: currency, maxAge: v.MaxAge})
+			case PriceSourceHttp:
+				if v.Url == "" || v.JsonPath == "" {
+					log.WithFields(log.Fields{"source": name}).Warn("http price source url or json path is empty")
+					continue
+				}
+				refresh := v.Refresh
+				if refresh <= 0 {
+					refresh = httpPriceRefresh
+				}
+				priceSources = append(priceSources, &httpPriceSource{name: name, currency: currency, maxAge: v.MaxAge,
+					url: v.Url, jsonPath: v.JsonPath, refresh: refresh, cache: make(map[int64]SourcePrice), attempt: make(map[int64]int64)})
+			case PriceSourceStatic:
+				if v.File == "" {
+					log.WithFields(log.Fields{"source": name}).Warn("static price source file is empty")
+					continue
+				}
+				priceSources = append(priceSources, &staticPriceSource{name: name, currency: currency, maxAge: v.MaxAge, file: v.File})
+			default:
+				log.WithFields(log.Fields{"source": name, "type": v.Type}).Warn("price source type unknown")
+			}
+		}
+		if len(priceSources) == 0 {
+			priceSources = append(priceSources, &dexPriceSource{name: PriceSourceDex, currency: DefaultPriceCurrency})
+		}
+	})
+	return priceSources
+}
+
+// GetFiatRates the configured units of each currency per USD
+func GetFiatRates() map[string]string {
+	rates := map[string]string{DefaultPriceCurrency: "1"}
+	for k, v := range conf.GetEnvConf().Price.FiatRates {
+		rates[strings.ToUpper(k)] = v
+	}
+	return rates
+}
+
+// convertPrice converts the price from one currency to another with the fiat rates
+func convertPrice(price decimal.Decimal, from, to string) (decimal.Decimal, bool) {
+	if from == to {
+		return price, true
+	}
+	rates := GetFiatRates()
+	fromRate, err := decimal.NewFromString(rates[from])
+	if err != nil || fromRate.IsZero() {
+		return decimal.Zero, false
+	}
+	toRate, err := decimal.NewFromString(rates[to])
+	if err != nil || toRate.IsZero() {
+		return decimal.Zero, false
+	}
+	return price.Mul(toRate).DivRound(fromRate, 30), true
+}
+
+func isPriceStale(src PriceSource, updatedAt, now int64) bool {
+	return src.MaxAge() > 0 && now-updatedAt > src.MaxAge()
+}
+
+// GetOraclePrices the token prices in the currency, the sources are queried in the configured order:
+// the first fresh price wins, if all prices are stale the first one is returned with the stale flag
+func GetOraclePrices(ecosystems []int64, currency string) ([]OraclePrice, error) {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = DefaultPriceCurrency
+	}
+	if _, ok := GetFiatRates()[currency]; !ok {
+		return nil, fmt.Errorf("currency %s doesn't not exist", currency)
+	}
+	now := time.Now().Unix()
+	found := make(map[int64]*OraclePrice)
+	remain := ecosystems
+	for _, src := range getPriceSources() {
+		if len(remain) == 0 {
+			break
+		}
+		prices, err := src.GetPrices(remain)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "source": src.Name()}).Warn("get source prices failed")
+			continue
+		}
+		var next []int64
+		for _, eco := range remain {
+			sp, ok := prices[eco]
+			if !ok || !sp.Price.IsPositive() {
+				next = append(next, eco)
+				continue
+			}
+			price, ok := convertPrice(sp.Price, src.Currency(), currency)
+			if !ok {
+				next = append(next, eco)
+				continue
+			}
+			stale := isPriceStale(src, sp.UpdatedAt, now)
+			if _, ok := found[eco]; !ok || !stale {
+				found[eco] = &OraclePrice{Ecosystem: eco, Price: price.String(), Currency: currency,
+					Source: src.Name(), UpdatedAt: sp.UpdatedAt, Stale: stale}
+			}
+			if stale {
+				next = append(next, eco)
+			}
+		}
+		remain = next
+	}
+
+	rets := make([]OraclePrice, len(ecosystems))
+	for i, eco := range ecosystems {
+		if v, ok := found[eco]; ok {
+			rets[i] = *v
+		} else {
+			rets[i] = OraclePrice{Ecosystem: eco, Price: "0", Currency: currency, Stale: true}
+		}
+	}
+	return rets, nil
+}
+
+// GetOraclePricesAt the historical token prices at the time, from the recorded dex price points.
+// The price points are only recorded when defi is enabled
+func GetOraclePricesAt(ecosystems []int64, t int64, currency string) ([]OraclePrice, error) {
+	if !conf.GetEnvConf().Defi.Enable {
+		return nil, errors.New("defi not enable")
+	}
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = DefaultPriceCurrency
+	}
+	dex := &dexPriceSource{name: PriceSourceDex, currency: DefaultPriceCurrency}
+	for _, src := range getPriceSources() {
+		if v, ok := src.(*dexPriceSource); ok {
+			dex = v
+			break
+		}
+	}
+	rets := make([]OraclePrice, len(ecosystems))
+	for i, eco := range ecosystems {
+		rlt, err := GetTokenPriceAt(eco, t)
+		if err != nil {
+			return nil, err
+		}
+		price, _ := decimal.NewFromString(rlt.Price)
+		price, ok := convertPrice(price, dex.Currency(), currency)
+		if !ok {
+			return nil, fmt.Errorf("currency %s doesn't not exist", currency)
+		}
+		rets[i] = OraclePrice{Ecosystem: eco, Price: price.String(), Currency: currency, Source: dex.Name(),
+			UpdatedAt: t, Stale: rlt.Block == 0 && eco != AllowRankEcosystem}
+	}
+	return rets, nil
+}
+
+// dexPriceSource the prices of the defi pairs with the rank ecosystem, the rank ecosystem token is priced in the currency
+type dexPriceSource struct {
+	name     string
+	currency string
+	maxAge   int64
+}
+
+func (s *dexPriceSource) Name() string {
+	return s.name
+}
+
+func (s *dexPriceSource) Currency() string {
+	return s.currency
+}
+
+func (s *dexPriceSource) MaxAge() int64 {
+	return s.maxAge
+}
+
+func (s *dexPriceSource) GetPrices(ecosystems []int64) (map[int64]SourcePrice, error) {
+	if !conf.GetEnvConf().Defi.Enable {
+		return nil, errors.New("defi disabled")
+	}
+	prices, err := GetTokenPrices(ecosystems)
+	if err != nil {
+		return nil, err
+	}
+	allPair.RLock()
+	updateTime := allPair.updateTime
+	allPair.RUnlock()
+	rets := make(map[int64]SourcePrice)
+	for eco, v := range prices {
+		price, err := decimal.NewFromString(v)
+		if err != nil {
+			continue
+		}
+		rets[eco] = SourcePrice{Price: price, UpdatedAt: updateTime}
+	}
+	return rets, nil
+}
+
+// httpPriceSource fetches the price of each ecosystem from a json api, {ecosystem} and {symbol} of the url and
+// the json path are replaced. The responses are cached for the refresh seconds, the cached price is kept if a request fails
+// and the failed ecosystems are not requested again before the refresh seconds
+type httpPriceSource struct {
+	name     string
+	currency string
+	maxAge   int64
+	url      string
+	jsonPath string
+	refresh  int64
+
+	sync.Mutex
+	cache   map[int64]SourcePrice
+	attempt map[int64]int64 //the last request time of each ecosystem
+}
+
+func (s *httpPriceSource) Name() string {
+	return s.name
+}
+
+func (s *httpPriceSource) Currency() string {
+	return s.currency
+}
+
+func (s *httpPriceSource) MaxAge() int64 {
+	return s.maxAge
+}
+
+func (s *httpPriceSource) GetPrices(ecosystems []int64) (map[int64]SourcePrice, error) {
+	now := time.Now().Unix()
+	var fetch []int64
+	s.Lock()
+	for _, eco := range ecosystems {
+		if v, ok := s.cache[eco]; ok && now-v.UpdatedAt < s.refresh {
+			continue
+		}
+		if now-s.attempt[eco] < s.refresh {
+			continue
+		}
+		s.attempt[eco] = now
+		fetch = append(fetch, eco)
+	}
+	s.Unlock()
+
+	//the requests are made out of the lock, the other callers get the cached prices meanwhile
+	fetched := make(map[int64]SourcePrice)
+	bodies := make(map[string]any)
+	for _, eco := range fetch {
+		symbol, _ := holderTokenInfo(eco)
+		replacer := strings.NewReplacer("{ecosystem}", strconv.FormatInt(eco, 10), "{symbol}", symbol)
+		reqUrl := replacer.Replace(s.url)
+		body, ok := bodies[reqUrl]
+		if !ok {
+			var err error
+			body, err = getPriceJson(reqUrl)
+			if err != nil {
+				log.WithFields(log.Fields{"error": err, "source": s.name, "url": reqUrl}).Warn("request http price failed")
+			}
+			bodies[reqUrl] = body
+		}
+		if body == nil {
+			continue
+		}
+		price, err := jsonPathDecimal(body, replacer.Replace(s.jsonPath))
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "source": s.name, "ecosystem": eco}).Warn("parse http price failed")
+			continue
+		}
+		fetched[eco] = SourcePrice{Price: price, UpdatedAt: now}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for eco, v := range fetched {
+		s.cache[eco] = v
+	}
+	rets := make(map[int64]SourcePrice)
+	for _, eco := range ecosystems {
+		if v, ok := s.cache[eco]; ok {
+			rets[eco] = v
+		}
+	}
+	return rets, nil
+}
+
+func getPriceJson(reqUrl string) (any, error) {
+	client := &http.Client{Timeout: httpPriceTimeout}
+	resp, err := client.Get(reqUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var body any
+	if err = json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// jsonPathDecimal the number at the dot separated path, the array elements are selected by index
+func jsonPathDecimal(data any, path string) (decimal.Decimal, error) {
+	cur := data
+	for _, key := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			val, ok := v[key]
+			if !ok {
+				return decimal.Zero, fmt.Errorf("json path %s doesn't not exist", path)
+			}
+			cur = val
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return decimal.Zero, fmt.Errorf("json path %s doesn't not exist", path)
+			}
+			cur = v[idx]
+		default:
+			return decimal.Zero, fmt.Errorf("json path %s doesn't not exist", path)
+		}
+	}
+	switch v := cur.(type) {
+	case string:
+		return decimal.NewFromString(v)
+	case float64:
+		return decimal.NewFromFloat(v), nil
+	default:
+		return decimal.Zero, fmt.Errorf("json path %s is not a number", path)
+	}
+}
+
+// staticPriceSource reads the prices from a fixture file for the offline setups:
+// {"updated_at":1700000000,"prices":{"1":"0.1"}}, the file modification time is used if updated_at is empty
+type staticPriceSource struct {
+	name     string
+	currency string
+	maxAge   int64
+	file     string
+}
+
+type staticPriceFile struct {
+	UpdatedAt int64             `json:"updated_at"`
+	Prices    map[string]string `json:"prices"`
+}
+
+func (s *staticPriceSource) Name() string {
+	return s.name
+}
+
+func (s *staticPriceSource) Currency() string {
+	return s.currency
+}
+
+func (s *staticPriceSource) MaxAge() int64 {
+	return s.maxAge
+}
+
+func (s *staticPriceSource) GetPrices(ecosystems []int64) (map[int64]SourcePrice, error) {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		return nil, err
+	}
+	var file staticPriceFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.UpdatedAt == 0 {
+		file.UpdatedAt = info.ModTime().Unix()
+	}
+	rets := make(map[int64]SourcePrice)
+	for _, eco := range ecosystems {
+		v, ok := file.Prices[strconv.FormatInt(eco, 10)]
+		if !ok {
+			continue
+		}
+		price, err := decimal.NewFromString(v)
+		if err != nil {
+			continue
+		}
+		rets[eco] = SourcePrice{Price: price, UpdatedAt: file.UpdatedAt}
+	}
+	return rets, nil
+}
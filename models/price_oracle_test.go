@@ -0,0 +1,251 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/shopspring/decimal"
+)
+
+type fakePriceSource struct {
+	name     string
+	currency string
+	maxAge   int64
+	prices   map[int64]SourcePrice
+	err      error
+}
+
+func (s *fakePriceSource) Name() string {
+	return s.name
+}
+
+func (s *fakePriceSource) Currency() string {
+	return s.currency
+}
+
+func (s *fakePriceSource) MaxAge() int64 {
+	return s.maxAge
+}
+
+func (s *fakePriceSource) GetPrices(ecosystems []int64) (map[int64]SourcePrice, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	rets := make(map[int64]SourcePrice)
+	for _, eco := range ecosystems {
+		if v, ok := s.prices[eco]; ok {
+			rets[eco] = v
+		}
+	}
+	return rets, nil
+}
+
+func setPriceSources(t *testing.T, sources ...PriceSource) {
+	priceSourcesOnce.Do(func() {})
+	saved := priceSources
+	savedRates := conf.GetEnvConf().Price.FiatRates
+	t.Cleanup(func() {
+		priceSources = saved
+		conf.GetEnvConf().Price.FiatRates = savedRates
+	})
+	priceSources = sources
+	conf.GetEnvConf().Price.FiatRates = map[string]string{"EUR": "0.5"}
+}
+
+func TestGetOraclePrices(t *testing.T) {
+	now := time.Now().Unix()
+	price := func(v string, updatedAt int64) SourcePrice {
+		return SourcePrice{Price: decimal.RequireFromString(v), UpdatedAt: updatedAt}
+	}
+	tests := []struct {
+		name     string
+		sources  []PriceSource
+		currency string
+		want     []OraclePrice
+		wantErr  bool
+	}{
+		{
+			name: "first fresh price wins",
+			sources: []PriceSource{
+				&fakePriceSource{name: "a", currency: "USD", prices: map[int64]SourcePrice{1: price("2", now)}},
+				&fakePriceSource{name: "b", currency: "USD", prices: map[int64]SourcePrice{1: price("3", now)}},
+			},
+			want: []OraclePrice{{Ecosystem: 1, Price: "2", Currency: "USD", Source: "a", UpdatedAt: now}},
+		},
+		{
+			name: "failed and missing sources fall back",
+			sources: []PriceSource{
+				&fakePriceSource{name: "a", err: errors.New("down")},
+				&fakePriceSource{name: "b", currency: "USD", prices: map[int64]SourcePrice{1: price("0", now)}},
+				&fakePriceSource{name: "c", currency: "USD", prices: map[int64]SourcePrice{1: price("4", now), 2: price("5", now)}},
+			},
+			want: []OraclePrice{
+				{Ecosystem: 1, Price: "4", Currency: "USD", Source: "c", UpdatedAt: now},
+				{Ecosystem: 2, Price: "5", Currency: "USD", Source: "c", UpdatedAt: now},
+			},
+		},
+		{
+			name: "stale price is replaced by a fresh one",
+			sources: []PriceSource{
+				&fakePriceSource{name: "a", currency: "USD", maxAge: 60, prices: map[int64]SourcePrice{1: price("2", now-120)}},
+				&fakePriceSource{name: "b", currency: "USD", prices: map[int64]SourcePrice{1: price("3", now-120)}},
+			},
+			want: []OraclePrice{{Ecosystem: 1, Price: "3", Currency: "USD", Source: "b", UpdatedAt: now - 120}},
+		},
+		{
+			name: "all stale returns the first one",
+			sources: []PriceSource{
+				&fakePriceSource{name: "a", currency: "USD", maxAge: 60, prices: map[int64]SourcePrice{1: price("2", now-120)}},
+				&fakePriceSource{name: "b", currency: "USD", maxAge: 60, prices: map[int64]SourcePrice{1: price("3", now-120)}},
+			},
+			want: []OraclePrice{{Ecosystem: 1, Price: "2", Currency: "USD", Source: "a", UpdatedAt: now - 120, Stale: true}},
+		},
+		{
+			name:     "price is converted with the fiat rates",
+			sources:  []PriceSource{&fakePriceSource{name: "a", currency: "USD", prices: map[int64]SourcePrice{1: price("2", now)}}},
+			currency: "eur",
+			want:     []OraclePrice{{Ecosystem: 1, Price: "1", Currency: "EUR", Source: "a", UpdatedAt: now}},
+		},
+		{
+			name:    "unknown price",
+			sources: []PriceSource{&fakePriceSource{name: "a", currency: "USD"}},
+			want:    []OraclePrice{{Ecosystem: 1, Price: "0", Currency: "USD", Stale: true}},
+		},
+		{
+			name:     "unknown currency",
+			sources:  []PriceSource{&fakePriceSource{name: "a", currency: "USD"}},
+			currency: "JPY",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setPriceSources(t, tt.sources...)
+			var ecosystems []int64
+			for _, v := range tt.want {
+				ecosystems = append(ecosystems, v.Ecosystem)
+			}
+			if tt.wantErr {
+				ecosystems = []int64{1}
+			}
+			got, err := GetOraclePrices(ecosystems, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d prices, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("price %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStaticPriceSource(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "prices.json")
+	data, _ := json.Marshal(staticPriceFile{UpdatedAt: 1700000000, Prices: map[string]string{"1": "0.1", "2": "bad"}})
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := &staticPriceSource{name: "static", currency: "USD", maxAge: 60, file: file}
+	prices, err := src.GetPrices([]int64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prices) != 1 || prices[1].Price.String() != "0.1" || prices[1].UpdatedAt != 1700000000 {
+		t.Fatalf("prices = %+v", prices)
+	}
+	if !isPriceStale(src, prices[1].UpdatedAt, 1700000061) || isPriceStale(src, prices[1].UpdatedAt, 1700000060) {
+		t.Fatal("static price staleness mismatch")
+	}
+}
+
+func TestHttpPriceSourceBackoff(t *testing.T) {
+	var (
+		requests int
+		status   = http.StatusInternalServerError
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"price":"0.2"}`))
+	}))
+	defer server.Close()
+
+	src := &httpPriceSource{name: "http", currency: "USD", url: server.URL + "/{ecosystem}", jsonPath: "price", refresh: 60,
+		cache: make(map[int64]SourcePrice), attempt: make(map[int64]int64)}
+	for i := 0; i < 3; i++ {
+		prices, err := src.GetPrices([]int64{2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(prices) != 0 {
+			t.Fatalf("prices = %+v, want none", prices)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("requests = %d, the failed request must not be retried before the refresh", requests)
+	}
+
+	status = http.StatusOK
+	src.attempt[2] -= src.refresh
+	prices, err := src.GetPrices([]int64{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || prices[2].Price.String() != "0.2" {
+		t.Fatalf("requests = %d, prices = %+v", requests, prices)
+	}
+	if _, err = src.GetPrices([]int64{2}); err != nil || requests != 2 {
+		t.Fatalf("requests = %d, the cached price must be used before the refresh", requests)
+	}
+}
+
+func TestJsonPathDecimal(t *testing.T) {
+	var body any
+	raw := `{"data":{"price":"1.25","list":[{"usd":0.5},{"usd":"x"}],"name":"ibax"}}`
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "data.price", want: "1.25"},
+		{path: "data.list.0.usd", want: "0.5"},
+		{path: "data.list.1.usd", wantErr: true},
+		{path: "data.list.2.usd", wantErr: true},
+		{path: "data.list.a", wantErr: true},
+		{path: "data.none", wantErr: true},
+		{path: "data.name.x", wantErr: true},
+		{path: "data", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := jsonPathDecimal(body, tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("jsonPathDecimal(%s) error = %v", tt.path, err)
+		}
+		if !tt.wantErr && got.String() != tt.want {
+			t.Errorf("jsonPathDecimal(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
@@ -207,23 +207,26 @@ type EcosystemDetailInfoResponse struct {
 	Circulations      string  `json:"circulations"`
 	FollowFuel        float64 `json:"follow_fuel"`
 
-	Registered       string            `json:"registered"`
-	Country          string            `json:"country"`
-	RegistrationNo   string            `json:"registration_no"`
-	RegistrationType string            `json:"registration_type"`
-	WebPage          string            `json:"web_page"`
-	Social           map[string]string `json:"social"`
-	Digits           int               `json:"digits"`
-	BridgeInfo       *bridgeInfo       `json:"bridge_info"`
+	Registered       string                `json:"registered"`
+	Country          string                `json:"country"`
+	RegistrationNo   string                `json:"registration_no"`
+	RegistrationType string                `json:"registration_type"`
+	WebPage          string                `json:"web_page"`
+	Social           map[string]string     `json:"social"`
+	Digits           int                   `json:"digits"`
+	BridgeInfo       *bridgeInfo           `json:"bridge_info"`
+	Profile          *EcosystemProfileInfo `json:"profile"` //the approved founder signed profile
 }
 
 type EcosystemTxList struct {
-	Hash     string `json:"hash"`
-	BlockId  int64  `json:"block_id"`
-	Time     int64  `json:"time"`
-	Contract string `json:"contract"`
-	Address  string `json:"address"`
-	Status   int32  `json:"status"`
+	Hash     string            `json:"hash"`
+	BlockId  int64             `json:"block_id"`
+	Time     int64             `json:"time"`
+	Contract string            `json:"contract"`
+	Address  string            `json:"address"`
+	Iname    string            `json:"iname,omitempty"`
+	Label    *AddressLabelInfo `json:"label,omitempty" gorm:"-"`
+	Status   int32             `json:"status"`
 }
 
 type EcosystemSearchResponse struct {
@@ -239,16 +242,17 @@ type GeneralResponse struct {
 }
 
 type EcosystemTokenSymbolList struct {
-	Id             int64           `json:"id"`
-	Account        string          `json:"account"`
-	AccountName    string          `json:"account_name"`
-	Amount         decimal.Decimal `json:"amount"`
-	AccountedFor   decimal.Decimal `json:"accounted_for"`
-	TokenSymbol    string          `json:"token_symbol"`
-	Digits         int             `json:"digits"`
-	FrontCommittee bool            `json:"front_committee"`
-	Committee      bool            `json:"committee"`
-	Activation     bool            `json:"activation"`
+	Id             int64             `json:"id"`
+	Account        string            `json:"account"`
+	AccountName    string            `json:"account_name"`
+	Label          *AddressLabelInfo `json:"label,omitempty" gorm:"-"`
+	Amount         decimal.Decimal   `json:"amount"`
+	AccountedFor   decimal.Decimal   `json:"accounted_for"`
+	TokenSymbol    string            `json:"token_symbol"`
+	Digits         int               `json:"digits"`
+	FrontCommittee bool              `json:"front_committee"`
+	Committee      bool              `json:"committee"`
+	Activation     bool              `json:"activation"`
 }
 
 type EcosystemMemberList struct {
@@ -442,6 +446,7 @@ type GasFeeChangeResponse struct {
 type HonorNodeListResponse struct {
 	NodeName        string          `json:"node_name"`
 	KeyID           string          `json:"key_id"`
+	Iname           string          `json:"iname,omitempty"`
 	Country         string          `json:"country"`
 	IconUrl         string          `json:"icon_url"`
 	GasFee          string          `json:"gas_fee"`
@@ -675,6 +680,7 @@ type NodeDetailResponse struct {
 	NodeListResponse
 	StakeRate string `json:"stake_rate"`
 	Account   string `json:"account"`
+	Iname     string `json:"iname,omitempty"`
 }
 
 type NodeVoteResponse struct {
@@ -885,14 +891,16 @@ type UtxoExplorer struct {
 }
 
 type AccountTxListResponse struct {
-	Hash          string `json:"hash"`
-	BlockId       int64  `json:"block_id"`
-	ContractName  string `json:"contract_name"`
-	Timestamp     int64  `json:"timestamp"`
-	Address       string `json:"address"`
-	Status        int32  `json:"status"`
-	EcosystemName string `json:"ecosystem_name"`
-	Ecosystem     int64  `json:"ecosystem"`
+	Hash          string            `json:"hash"`
+	BlockId       int64             `json:"block_id"`
+	ContractName  string            `json:"contract_name"`
+	Timestamp     int64             `json:"timestamp"`
+	Address       string            `json:"address"`
+	Iname         string            `json:"iname,omitempty"`
+	Label         *AddressLabelInfo `json:"label,omitempty" gorm:"-"`
+	Status        int32             `json:"status"`
+	EcosystemName string            `json:"ecosystem_name"`
+	Ecosystem     int64             `json:"ecosystem"`
 }
 
 type AccountTxInfoResponse struct {
@@ -241,12 +241,6 @@ type RatesInfo struct {
 	Rates     map[string]any `json:"rates"`
 }
 
-type BlockCCRatesInfo struct {
-	Code    int       `json:"code"`
-	Message string    `json:"message"`
-	Data    RatesInfo `json:"data"`
-}
-
 type ResponseTopDataBoby struct {
 	TopData           any `json:"topdata,omitempty"`
 	TopBlocks         any `json:"topblocks,omitempty"`
@@ -259,12 +253,6 @@ type ResponseDashboardTitle struct {
 	List any    `json:"list,omitempty"`
 }
 
-type BlockCCPriceInfo struct {
-	Code    int              `json:"code"`
-	Message string           `json:"message"`
-	Data    []map[string]any `json:"data"`
-}
-
 type StatisticsData struct {
 	AllTransactionsNum int64 `json:"transactions"`
 	ChainContractsNum  int64 `json:"contracts"`
@@ -0,0 +1,269 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SearchBlock       = "block"
+	SearchTransaction = "transaction"
+	SearchNftHash     = "nft_hash"
+	SearchAccount     = "account"
+	SearchKeyId       = "key_id"
+	SearchIName       = "iname"
+	SearchEcosystem   = "ecosystem"
+	SearchToken       = "token"
+	SearchNftMiner    = "nft_miner"
+	SearchNode        = "node"
+	SearchContract    = "contract"
+
+	searchMaxLimit = 50
+)
+
+// searchTypeWeight breaks the tie of suggestions with the same match score
+var searchTypeWeight = map[string]int{
+	SearchTransaction: 9, SearchBlock: 8, SearchAccount: 8, SearchEcosystem: 7, SearchToken: 7,
+	SearchIName: 6, SearchNode: 5, SearchNftHash: 5, SearchNftMiner: 4, SearchContract: 3, SearchKeyId: 2,
+}
+
+type SearchSuggestion struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"` //the value used to open the detail page
+	Label     string `json:"label"`
+	Ecosystem int64  `json:"ecosystem,omitempty"`
+	Score     int    `json:"score"`
+}
+
+type searchIndexItem struct {
+	key string //lower case
+	SearchSuggestion
+}
+
+// searchPrefixIndex items sorted by key, queried by binary search on the prefix
+type searchPrefixIndex struct {
+	items []searchIndexItem
+	sync.RWMutex
+}
+
+var SearchIndex = &searchPrefixIndex{}
+
+func (p *searchPrefixIndex) reset(items []searchIndexItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].key < items[j].key
+	})
+	p.Lock()
+	defer p.Unlock()
+	p.items = items
+}
+
+func (p *searchPrefixIndex) Len() int {
+	p.RLock()
+	defer p.RUnlock()
+	return len(p.items)
+}
+
+// Prefix returns the items whose key, or one word of the key, starts with the prefix
+func (p *searchPrefixIndex) Prefix(prefix string, limit int) []SearchSuggestion {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return nil
+	}
+	p.RLock()
+	defer p.RUnlock()
+	var rets []SearchSuggestion
+	seen := make(map[string]bool)
+	start := sort.Search(len(p.items), func(i int) bool {
+		return p.items[i].key >= prefix
+	})
+	for i := start; i < len(p.items) && strings.HasPrefix(p.items[i].key, prefix); i++ {
+		it := p.items[i]
+		id := it.Type + ":" + it.Value
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		it.Score = 50 + searchTypeWeight[it.Type]
+		if it.key == prefix && strings.ToLower(it.Label) == prefix {
+			it.Score = 100 + searchTypeWeight[it.Type]
+		} else if strings.HasPrefix(strings.ToLower(it.Label), prefix) {
+			it.Score = 70 + searchTypeWeight[it.Type]
+		}
+		rets = append(rets, it.SearchSuggestion)
+		if len(rets) >= limit*4 {
+			break
+		}
+	}
+	return rets
+}
+
+func newSearchIndexItems(sg SearchSuggestion) []searchIndexItem {
+	label := strings.ToLower(strings.TrimSpace(sg.Label))
+	if label == "" {
+		return nil
+	}
+	items := []searchIndexItem{{key: label, SearchSuggestion: sg}}
+	words := strings.FieldsFunc(label, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '@' || r == '.'
+	})
+	if len(words) > 1 {
+		for _, w := range words {
+			items = append(items, searchIndexItem{key: w, SearchSuggestion: sg})
+		}
+	}
+	return items
+}
+
+// RefreshSearchIndex rebuilds the type-ahead index of ecosystems, tokens, nodes, contracts and inames
+func RefreshSearchIndex() {
+	var items []searchIndexItem
+	Info.RLock()
+	for _, v := range Info.m {
+		name := v.Name
+		if v.Id == 1 && name == "" {
+			name = SysEcosystemName
+		}
+		items = append(items, newSearchIndexItems(SearchSuggestion{
+			Type: SearchEcosystem, Value: strconv.FormatInt(v.Id, 10), Label: name, Ecosystem: v.Id,
+		})...)
+		symbol := v.TokenSymbol
+		if v.Id == 1 {
+			symbol = SysTokenSymbol
+		}
+		items = append(items, newSearchIndexItems(SearchSuggestion{
+			Type: SearchToken, Value: strconv.FormatInt(v.Id, 10), Label: symbol, Ecosystem: v.Id,
+		})...)
+	}
+	Info.RUnlock()
+
+	for _, v := range HonorNodes {
+		items = append(items, newSearchIndexItems(SearchSuggestion{
+			Type: SearchNode, Value: strconv.FormatInt(v.NodePosition, 10), Label: v.NodeName,
+		})...)
+	}
+
+	var contracts []Contract
+	err := GetDB(nil).Select("id,name,ecosystem").Where("active = true OR active IS NULL").Find(&contracts).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("refresh search index contracts failed")
+	}
+	for _, v := range contracts {
+		items = append(items, newSearchIndexItems(SearchSuggestion{
+			Type: SearchContract, Value: "@" + strconv.FormatInt(v.EcosystemID, 10) + v.Name, Label: v.Name, Ecosystem: v.EcosystemID,
+		})...)
+	}
+
+	for _, v := range INames.List() {
+		items = append(items, newSearchIndexItems(SearchSuggestion{
+			Type: SearchIName, Value: v.Account, Label: v.Name, Ecosystem: 1,
+		})...)
+	}
+
+	SearchIndex.reset(items)
+}
+
+// searchNumber a pure number may be a block id, ecosystem id, nft miner id or key id
+func searchNumber(q string) []SearchSuggestion {
+	var rets []SearchSuggestion
+	id, err := strconv.ParseInt(q, 10, 64)
+	if err != nil {
+		return rets
+	}
+	if id > 0 {
+		var bk Block
+		if f, err := bk.GetMaxBlock(); err == nil && f && id <= bk.ID {
+			rets = append(rets, SearchSuggestion{Type: SearchBlock, Value: q, Label: "Block #" + q, Score: 100 + searchTypeWeight[SearchBlock]})
+		}
+		if info := Info.Get(id); info.Id == id {
+			label := info.Name
+			if id == 1 && label == "" {
+				label = SysEcosystemName
+			}
+			rets = append(rets, SearchSuggestion{Type: SearchEcosystem, Value: q, Label: label, Ecosystem: id, Score: 90 + searchTypeWeight[SearchEcosystem]})
+		}
+		if NftMinerReady {
+			var item NftMinerItems
+			if f, err := item.GetById(id); err == nil && f {
+				rets = append(rets, SearchSuggestion{Type: SearchNftMiner, Value: q, Label: "NFT Miner #" + q, Score: 90 + searchTypeWeight[SearchNftMiner]})
+			}
+		}
+	}
+	if id != 0 && len(q) >= 10 {
+		var k Key
+		if f, err := isFound(GetDB(nil).Table(k.TableName()).Select("id").Where("id = ?", id).Limit(1).Take(&k)); err == nil && f {
+			rets = append(rets, SearchSuggestion{Type: SearchKeyId, Value: converter.AddressToString(id), Label: q, Ecosystem: 1, Score: 95 + searchTypeWeight[SearchKeyId]})
+		}
+	}
+	return rets
+}
+
+func searchHashValue(q string) []SearchSuggestion {
+	hashHex, err := hex.DecodeString(q)
+	if err != nil {
+		return nil
+	}
+	var lt LogTransaction
+	if f, err := lt.GetByHash(hashHex); err == nil && f {
+		return []SearchSuggestion{{Type: SearchTransaction, Value: q, Label: q, Ecosystem: lt.EcosystemID, Score: 100 + searchTypeWeight[SearchTransaction]}}
+	}
+	var bk Block
+	if f, err := isFound(GetDB(nil).Select("id").Where("hash = ?", hashHex).Take(&bk)); err == nil && f {
+		return []SearchSuggestion{{Type: SearchBlock, Value: strconv.FormatInt(bk.ID, 10), Label: "Block #" + strconv.FormatInt(bk.ID, 10), Score: 100 + searchTypeWeight[SearchBlock]}}
+	}
+	if NftMinerReady {
+		var item NftMinerItems
+		if f, err := item.GetByTokenHash(q); err == nil && f {
+			return []SearchSuggestion{{Type: SearchNftHash, Value: strconv.FormatInt(item.ID, 10), Label: q, Score: 100 + searchTypeWeight[SearchNftHash]}}
+		}
+	}
+	return nil
+}
+
+// GlobalSearch detects what the query is and returns ranked typed suggestions
+func GlobalSearch(q string, limit int) ([]SearchSuggestion, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, errors.New("search query is empty")
+	}
+	if limit <= 0 || limit > searchMaxLimit {
+		limit = 10
+	}
+	var rets []SearchSuggestion
+
+	lower := strings.ToLower(strings.TrimPrefix(q, "0x"))
+	if len(lower) == 64 {
+		rets = append(rets, searchHashValue(lower)...)
+	}
+	if isAddressFormat(q) {
+		if info := ValidateAddress(q); info.Valid {
+			rets = append(rets, SearchSuggestion{Type: SearchAccount, Value: info.Address, Label: info.Address, Ecosystem: 1, Score: 100 + searchTypeWeight[SearchAccount]})
+		}
+	}
+	if _, err := strconv.ParseInt(q, 10, 64); err == nil {
+		rets = append(rets, searchNumber(q)...)
+	}
+	rets = append(rets, SearchIndex.Prefix(q, limit)...)
+
+	sort.SliceStable(rets, func(i, j int) bool {
+		if rets[i].Score != rets[j].Score {
+			return rets[i].Score > rets[j].Score
+		}
+		return len(rets[i].Label) < len(rets[j].Label)
+	})
+	if len(rets) > limit {
+		rets = rets[:limit]
+	}
+	return rets, nil
+}
@@ -0,0 +1,172 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	SupplyTotal       = "total_supply"
+	SupplyCirculating = "circulating_supply"
+	SupplyMax         = "max_supply"
+)
+
+// SupplyLocked the locked parts of the supply, all amounts are in token units
+//
+//	assign_vesting: foundation, partners, private, public and dev team allocations that are not released yet (ecosystem 1)
+//	airdrop_lock: airdrop amounts that are still locked up (ecosystem 1)
+//	airdrop_staking: airdrop amounts that are staked (ecosystem 1)
+//	nft_staking: tokens staked by nft miners (ecosystem 1)
+//	node_staking: candidate node deposits that are not withdrawn (ecosystem 1)
+//	miner_reserve: tokens not mined yet by nft miners and mint nodes, they are excluded from total supply (ecosystem 1)
+//	burned: the total_burned of the burn ledger: the contract combustion, the token burns, the utxo combustion and the utxo
+//	transfers to the black hole address, they are excluded from total supply
+type SupplyLocked struct {
+	AssignVesting  string `json:"assign_vesting"`
+	AirdropLock    string `json:"airdrop_lock"`
+	AirdropStaking string `json:"airdrop_staking"`
+	NftStaking     string `json:"nft_staking"`
+	NodeStaking    string `json:"node_staking"`
+	MinerReserve   string `json:"miner_reserve"`
+	Burned         string `json:"burned"`
+}
+
+// SupplyResponse
+// max_supply: the max amount of tokens, for the ecosystem which allows additional issuance it is the issued amount
+// total_supply: max_supply - miner_reserve - burned
+// circulating_supply: the tokens held by the accounts and the unspent utxo outputs
+type SupplyResponse struct {
+	Ecosystem         int64        `json:"ecosystem"`
+	TokenSymbol       string       `json:"token_symbol"`
+	Digits            int          `json:"digits"`
+	TotalSupply       string       `json:"total_supply"`
+	CirculatingSupply string       `json:"circulating_supply"`
+	MaxSupply         string       `json:"max_supply"`
+	Locked            SupplyLocked `json:"locked"`
+}
+
+func getEcosystemIssued(ecosystem int64) (decimal.Decimal, error) {
+	type result struct {
+		Supply   decimal.Decimal
+		Emission decimal.Decimal
+	}
+	var rets result
+	err := GetDB(nil).Raw(`
+SELECT COALESCE((SELECT amount FROM "1_history" WHERE type = 6 AND ecosystem = ? ORDER BY id ASC LIMIT 1),0) AS supply,
+	COALESCE((SELECT sum(amount) FROM "1_history" WHERE type = 29 AND ecosystem = ?),0) AS emission
+`, ecosystem, ecosystem).Take(&rets).Error
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return rets.Supply.Add(rets.Emission), nil
+}
+
+func GetSupply(ecosystem int64) (*SupplyResponse, error) {
+	var (
+		rets    SupplyResponse
+		maxSup  decimal.Decimal
+		reserve decimal.Decimal
+		assign  decimal.Decimal
+		nft     decimal.Decimal
+		node    decimal.Decimal
+		adLock  decimal.Decimal
+		adStake decimal.Decimal
+		err     error
+	)
+	if Info.Get(ecosystem).Id != ecosystem {
+		return nil, errors.New("ecosystem doesn't not exist")
+	}
+	rets.Ecosystem = ecosystem
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(ecosystem)
+
+	cir, err := GetCirculations(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	circulations, _ := decimal.NewFromString(cir)
+	burned, err := GetTotalBurned(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+
+	if ecosystem == 1 {
+		maxSup = TotalSupplyToken
+		reserve = NftMinerTotalBalance.Add(MintNodeTotalBalance)
+		if AssignReady {
+			assign = AssignTotalBalance
+		}
+		if AirdropReady {
+			adLock = nowAirdropLockAll
+			adStake = nowAirdropStakingAll
+		}
+		var miner NftMinerStaking
+		_, nft, err = miner.GetAllStakeAmount()
+		if err != nil {
+			return nil, err
+		}
+		if NodeReady {
+			var staking SumAmount
+			err = GetDB(nil).Table("1_candidate_node_decisions").Select("coalesce(sum(earnest),'0')as sum").
+				Where("decision <> 3").Take(&staking.Sum).Error
+			if err != nil {
+				return nil, err
+			}
+			node = staking.Sum
+		}
+	} else {
+		maxSup, err = getEcosystemIssued(ecosystem)
+		if err != nil {
+			return nil, err
+		}
+	}
+	total := supplyTotal(maxSup, reserve, burned)
+
+	unit := func(d decimal.Decimal) string {
+		return d.Shift(int32(-rets.Digits)).String()
+	}
+	rets.MaxSupply = unit(maxSup)
+	rets.TotalSupply = unit(total)
+	rets.CirculatingSupply = unit(circulations)
+	rets.Locked = SupplyLocked{
+		AssignVesting:  unit(assign),
+		AirdropLock:    unit(adLock),
+		AirdropStaking: unit(adStake),
+		NftStaking:     unit(nft),
+		NodeStaking:    unit(node),
+		MinerReserve:   unit(reserve),
+		Burned:         unit(burned),
+	}
+	return &rets, nil
+}
+
+// supplyTotal the total supply: max_supply - miner_reserve - burned, it is not negative
+func supplyTotal(maxSup, reserve, burned decimal.Decimal) decimal.Decimal {
+	total := maxSup.Sub(reserve).Sub(burned)
+	if total.LessThan(decimal.Zero) {
+		return decimal.Zero
+	}
+	return total
+}
+
+// GetSupplyValue one of total_supply, circulating_supply and max_supply in token units
+func GetSupplyValue(ecosystem int64, name string) (string, error) {
+	rets, err := GetSupply(ecosystem)
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case SupplyTotal:
+		return rets.TotalSupply, nil
+	case SupplyCirculating:
+		return rets.CirculatingSupply, nil
+	case SupplyMax:
+		return rets.MaxSupply, nil
+	}
+	return "", errors.New("supply type invalid")
+}
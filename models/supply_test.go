@@ -0,0 +1,38 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSupplyTotal(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		name    string
+		maxSup  string
+		reserve string
+		burned  []string //the burn ledger rows: contract, token(1_history type 7), utxo, black hole
+		want    string
+	}{
+		{name: "no burn", maxSup: "1000", reserve: "100", want: "900"},
+		{name: "contract burn", maxSup: "1000", reserve: "100", burned: []string{"50"}, want: "850"},
+		{name: "token burn", maxSup: "1000", reserve: "100", burned: []string{"50", "300"}, want: "550"},
+		{name: "all burn sources", maxSup: "1000", reserve: "100", burned: []string{"50", "300", "20", "30"}, want: "500"},
+		{name: "not negative", maxSup: "1000", reserve: "900", burned: []string{"300"}, want: "0"},
+	}
+	for _, tt := range tests {
+		burned := decimal.Zero
+		for _, v := range tt.burned {
+			burned = burned.Add(d(v))
+		}
+		if got := supplyTotal(d(tt.maxSup), d(tt.reserve), burned); got.String() != tt.want {
+			t.Errorf("%s: supplyTotal = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"strconv"
 	"sync"
+	"time"
 )
 
 const (
@@ -79,7 +80,8 @@ type PairEcosystem struct {
 }
 
 type pairList struct {
-	pairs []PairEcosystem
+	pairs      []PairEcosystem
+	updateTime int64
 	sync.RWMutex
 }
 
@@ -117,6 +119,7 @@ func updatePair(list []PairEcosystem) {
 	allPair.Lock()
 	defer allPair.Unlock()
 	allPair.pairs = list
+	allPair.updateTime = time.Now().Unix()
 }
 
 func GetTokenPrices(ecosystems []int64) (prices map[int64]string, err error) {
@@ -129,8 +132,6 @@ func GetTokenPrices(ecosystems []int64) (prices map[int64]string, err error) {
 	}
 	allPair.RLock()
 	defer allPair.RUnlock()
-	digits := int(EcoDigits.GetInt(AllowRankEcosystem, 0))
-	num1 := decimal.NewFromFloat(math.Pow10(digits))
 	for _, ecosystemId := range ecosystems {
 		var price = "0"
 		if ecosystemId == AllowRankEcosystem {
@@ -141,12 +142,7 @@ func GetTokenPrices(ecosystems []int64) (prices map[int64]string, err error) {
 			if (pair.Ecosystem1 == AllowRankEcosystem && pair.Ecosystem2 == ecosystemId ||
 				pair.Ecosystem2 == AllowRankEcosystem && pair.Ecosystem1 == ecosystemId) &&
 				!pair.Reserve2.IsZero() && !pair.Reserve1.IsZero() {
-				num2 := decimal.NewFromFloat(math.Pow10(int(EcoDigits.GetInt(ecosystemId, 0)))) //Value of each token
-				if pair.Ecosystem1 == ecosystemId {
-					price = pair.Reserve2.DivRound(pair.Reserve1, 30).Mul(num2).DivRound(num1, 30).String()
-				} else {
-					price = pair.Reserve1.DivRound(pair.Reserve2, 30).Mul(num2).DivRound(num1, 30).String()
-				}
+				price = getPairPrice(ecosystemId, pair.Ecosystem1, pair.Reserve1, pair.Reserve2).String()
 			}
 		}
 		prices[ecosystemId] = price
@@ -154,6 +150,19 @@ func GetTokenPrices(ecosystems []int64) (prices map[int64]string, err error) {
 	return
 }
 
+// getPairPrice the price of one token of the ecosystem in AllowRankEcosystem tokens
+func getPairPrice(ecosystemId, ecosystem1 int64, reserve1, reserve2 decimal.Decimal) decimal.Decimal {
+	if reserve1.IsZero() || reserve2.IsZero() {
+		return decimal.Zero
+	}
+	num1 := decimal.NewFromFloat(math.Pow10(int(EcoDigits.GetInt(AllowRankEcosystem, 0))))
+	num2 := decimal.NewFromFloat(math.Pow10(int(EcoDigits.GetInt(ecosystemId, 0)))) //Value of each token
+	if ecosystem1 == ecosystemId {
+		return reserve2.DivRound(reserve1, 30).Mul(num2).DivRound(num1, 30)
+	}
+	return reserve1.DivRound(reserve2, 30).Mul(num2).DivRound(num1, 30)
+}
+
 type TokensInfo struct {
 	Name      string `gorm:"column:name"`
 	Address   string `gorm:"column:address"`
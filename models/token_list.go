@@ -0,0 +1,478 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/IBAX-io/go-ibax/packages/block"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	tokenListDefaultName = "IBAX Token List"
+	tokenListCacheTime   = 5 * 60
+
+	TokenListTagPlatform = "platform"
+	TokenListTagBridged  = "bridged"
+)
+
+var (
+	tokenListLock  sync.Mutex
+	tokenListCache *TokenList
+	tokenListTime  int64
+
+	tokenListChainIdCache int64
+
+	//the characters out of the token list schema patterns
+	tokenListTagReg   = regexp.MustCompile(`[^a-z0-9_]+`)
+	tokenListWordReg  = regexp.MustCompile(`[^a-zA-Z0-9_ ]+`)
+	tokenListNameReg  = regexp.MustCompile(`[^ a-zA-Z0-9_.'+\-%/À-ÖØ-öø-ÿ:&\[\]()]+`)
+	tokenListDescReg  = regexp.MustCompile(`[^ a-zA-Z0-9_.,:]+`)
+	tokenListSpaceReg = regexp.MustCompile(`\s+`)
+)
+
+// the limits of the token list schema, https://uniswap.org/tokenlist.schema.json
+const (
+	tokenListNameMax      = 30
+	tokenListKeywordMax   = 20
+	tokenListKeywordsMax  = 20
+	tokenListTagIdMax     = 10
+	tokenListTagsMax      = 20
+	tokenListTagNameMax   = 20
+	tokenListTagDescMax   = 200
+	tokenListTokenNameMax = 40
+	tokenListSymbolMax    = 20
+	tokenListTokenTagsMax = 10
+)
+
+// TokenListVersion a version of the token list, a new version is recorded when the entries change
+type TokenListVersion struct {
+	ID        int64  `gorm:"primary_key;not null"`
+	Major     int    `gorm:"not null"`
+	Minor     int    `gorm:"not null"`
+	Patch     int    `gorm:"not null"`
+	Hash      string `gorm:"not null"`           //the hash of all entries
+	Entries   string `gorm:"not null;type:text"` //json object, token address:entry hash
+	CreatedAt int64  `gorm:"not null"`
+}
+
+type TokenListSemver struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+type TokenListTag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type TokenListToken struct {
+	ChainId    int64          `json:"chainId"`
+	Address    string         `json:"address"`
+	Name       string         `json:"name"`
+	Symbol     string         `json:"symbol"`
+	Decimals   int            `json:"decimals"`
+	LogoURI    string         `json:"logoURI,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// TokenList the token list in the Uniswap token list schema
+type TokenList struct {
+	Name      string                  `json:"name"`
+	Timestamp string                  `json:"timestamp"`
+	Version   TokenListSemver         `json:"version"`
+	Keywords  []string                `json:"keywords,omitempty"`
+	LogoURI   string                  `json:"logoURI,omitempty"`
+	Tags      map[string]TokenListTag `json:"tags,omitempty"`
+	Tokens    []TokenListToken        `json:"tokens"`
+}
+
+func (p *TokenListVersion) TableName() string {
+	return "token_list_version"
+}
+
+func (p *TokenListVersion) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func InitTokenListVersion() error {
+	var p TokenListVersion
+	return p.CreateTable()
+}
+
+func (p *TokenListVersion) GetLast() (bool, error) {
+	return isFound(GetDB(nil).Order("id desc").Take(p))
+}
+
+// tokenListClean replaces the characters matched by reg with a space, the result is trimmed to max characters
+func tokenListClean(s string, reg *regexp.Regexp, max int) string {
+	s = strings.TrimSpace(tokenListSpaceReg.ReplaceAllString(reg.ReplaceAllString(s, " "), " "))
+	return tokenListTruncate(s, max)
+}
+
+func tokenListTruncate(s string, max int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) > max {
+		return strings.TrimSpace(string(r[:max]))
+	}
+	return string(r)
+}
+
+// tokenListTag the tag id of the name, only word characters are allowed
+func tokenListTag(name string) string {
+	id := strings.Trim(tokenListTagReg.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	return strings.Trim(tokenListTruncate(id, tokenListTagIdMax), "_")
+}
+
+// tokenListAddress the ibax tokens have no contract address, the ecosystem id is left padded to an address of 20 bytes
+func tokenListAddress(ecosystem int64) string {
+	return fmt.Sprintf("0x%040x", uint64(ecosystem))
+}
+
+func tokenListEcoTag(info string) string {
+	if info == "" {
+		return ""
+	}
+	minfo := make(map[string]any)
+	if err := json.Unmarshal([]byte(info), &minfo); err != nil {
+		return ""
+	}
+	v, ok := minfo["tag"]
+	if !ok {
+		return ""
+	}
+	value, _ := strconv.Atoi(fmt.Sprint(v))
+	if value <= 0 {
+		return ""
+	}
+	tag := ecoTags.GetId(value, "-")
+	if tag == "-" {
+		return ""
+	}
+	return tag
+}
+
+// tokenListTags the tags of the list by id. The ids are truncated, so the tags that share the prefix of an id
+// get the id with a number suffix. No tag is added over the max tags of the schema
+type tokenListTags struct {
+	tags map[string]TokenListTag
+	ids  map[TokenListTag]string
+}
+
+func newTokenListTags() *tokenListTags {
+	return &tokenListTags{tags: make(map[string]TokenListTag), ids: make(map[TokenListTag]string)}
+}
+
+// add returns the id of the tag, empty when the id is invalid or the list has no room for a new tag
+func (t *tokenListTags) add(id string, tag TokenListTag) string {
+	if v, ok := t.ids[tag]; ok {
+		return v
+	}
+	if id == "" || len(t.tags) >= tokenListTagsMax {
+		return ""
+	}
+	base := id
+	for i := 2; ; i++ {
+		if _, ok := t.tags[id]; !ok {
+			break
+		}
+		suffix := strconv.Itoa(i)
+		id = strings.Trim(tokenListTruncate(base, tokenListTagIdMax-len(suffix)), "_") + suffix
+	}
+	t.tags[id] = tag
+	t.ids[tag] = id
+	return id
+}
+
+type tokenListBridge struct {
+	ChainId      int64
+	TokenAddress string
+}
+
+// tokenListSource the ecosystem token and the data of the caches that make up an entry
+type tokenListSource struct {
+	Ecosystem Ecosystem
+	Decimals  int
+	LogoURI   string
+	EcoTag    string
+	Bridge    *tokenListBridge
+}
+
+// newTokenListToken the entry of the ecosystem token, the tags used are added to tags
+func newTokenListToken(chainId int64, src tokenListSource, tags *tokenListTags) TokenListToken {
+	v := src.Ecosystem
+	name := v.TokenName
+	if name == "" {
+		name = v.Name
+	}
+	item := TokenListToken{
+		ChainId:    chainId,
+		Address:    tokenListAddress(v.ID),
+		Name:       tokenListClean(name, tokenListNameReg, tokenListTokenNameMax),
+		Symbol:     tokenListTruncate(tokenListSpaceReg.ReplaceAllString(v.TokenSymbol, ""), tokenListSymbolMax),
+		Decimals:   src.Decimals,
+		LogoURI:    src.LogoURI,
+		Extensions: map[string]any{"ecosystem": v.ID},
+	}
+	if item.Name == "" {
+		item.Name = tokenListClean(item.Symbol, tokenListNameReg, tokenListTokenNameMax)
+	}
+	if v.ID == 1 {
+		if id := tags.add(TokenListTagPlatform, TokenListTag{Name: "Platform", Description: "The platform token of the chain"}); id != "" {
+			item.Tags = append(item.Tags, id)
+		}
+	}
+	if b := src.Bridge; b != nil {
+		if id := tags.add(TokenListTagBridged, TokenListTag{Name: "Bridged", Description: "Tokens bridged from other chains"}); id != "" {
+			item.Tags = append(item.Tags, id)
+		}
+		item.Extensions["bridgeInfo"] = map[string]any{
+			strconv.FormatInt(b.ChainId, 10): map[string]any{"tokenAddress": b.TokenAddress},
+		}
+	}
+	if id := tokenListTag(src.EcoTag); id != "" && len(item.Tags) < tokenListTokenTagsMax {
+		tagName := tokenListClean(src.EcoTag, tokenListWordReg, tokenListTagNameMax)
+		if tagName == "" {
+			tagName = id
+		}
+		id = tags.add(id, TokenListTag{Name: tagName,
+			Description: tokenListClean("Ecosystems tagged "+tagName, tokenListDescReg, tokenListTagDescMax)})
+		if id != "" {
+			item.Tags = append(item.Tags, id)
+		}
+	}
+	return item
+}
+
+// buildTokenListTokens the entries of all ecosystems with a token, the bridged tokens carry the address
+// of the other chain in extensions.bridgeInfo
+func buildTokenListTokens(chainId int64) ([]TokenListToken, map[string]TokenListTag, error) {
+	var list []Ecosystem
+	err := GetDB(nil).Select("id,info,token_symbol,token_name,digits,name").Where("token_symbol <> ''").Order("id asc").Find(&list).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bridges := make(map[int64]*tokenListBridge)
+	if BridgeReady {
+		var tokens []BridgeToken
+		if err = GetDB(nil).Where("status = 1").Find(&tokens).Error; err != nil {
+			return nil, nil, err
+		}
+		var settings []BridgeSettings
+		if err = GetDB(nil).Find(&settings).Error; err != nil {
+			return nil, nil, err
+		}
+		chains := make(map[int64]int64)
+		for _, s := range settings {
+			chains[s.Id] = s.ChainId
+		}
+		for _, t := range tokens {
+			bridges[t.Ecosystem] = &tokenListBridge{ChainId: chains[t.SettingId], TokenAddress: t.TokenAddress}
+		}
+	}
+
+	tags := newTokenListTags()
+	tokens := make([]TokenListToken, 0, len(list))
+	for _, v := range list {
+		src := tokenListSource{
+			Ecosystem: v,
+			Decimals:  EcoDigits.GetInt(v.ID, v.Digits),
+			LogoURI:   Info.Get(v.ID).LogoURI,
+			EcoTag:    tokenListEcoTag(v.Info),
+			Bridge:    bridges[v.ID],
+		}
+		if src.LogoURI == "" {
+			if hash := GetLogoHashByInfo(v.Info); hash != "" {
+				src.LogoURI = conf.GetEnvConf().Url.Base + ApiPath + "get_eco_attachment_export/" + hash
+			}
+		}
+		tokens = append(tokens, newTokenListToken(chainId, src, tags))
+	}
+	return tokens, tags.tags, nil
+}
+
+// tokenListChainId the configured chain id, or the network id signed in the latest contract transaction
+func tokenListChainId() (int64, error) {
+	if id := conf.GetEnvConf().TokenList.ChainId; id > 0 {
+		return id, nil
+	}
+	if tokenListChainIdCache > 0 {
+		return tokenListChainIdCache, nil
+	}
+	var bk Block
+	f, err := isFound(GetDB(nil).Select("id,data").Where("tx > 0").Order("id desc").Take(&bk))
+	if err != nil {
+		return 0, err
+	}
+	if f {
+		blck, err := block.UnmarshallBlock(bytes.NewBuffer(bk.Data), false)
+		if err != nil {
+			return 0, err
+		}
+		for _, tx := range blck.Transactions {
+			if !tx.IsSmartContract() {
+				continue
+			}
+			if sc := tx.SmartContract(); sc != nil && sc.TxSmart.Header != nil && sc.TxSmart.Header.NetworkID > 0 {
+				tokenListChainIdCache = sc.TxSmart.Header.NetworkID
+				return tokenListChainIdCache, nil
+			}
+		}
+	}
+	return 0, errors.New("network id unknown, set the token_list chain_id")
+}
+
+func tokenListEntries(tokens []TokenListToken) (map[string]string, string) {
+	entries := make(map[string]string)
+	all := sha256.New()
+	for _, v := range tokens {
+		data, _ := json.Marshal(v)
+		sum := sha256.Sum256(data)
+		entries[v.Address] = hex.EncodeToString(sum[:])
+		all.Write(data)
+	}
+	return entries, hex.EncodeToString(all.Sum(nil))
+}
+
+// nextTokenListVersion bumps the version like the token list specification:
+// removed tokens bump the major, added tokens bump the minor, changed tokens bump the patch
+func nextTokenListVersion(last *TokenListVersion, entries map[string]string) TokenListSemver {
+	ver := TokenListSemver{Major: last.Major, Minor: last.Minor, Patch: last.Patch}
+	var prev map[string]string
+	if err := json.Unmarshal([]byte(last.Entries), &prev); err != nil {
+		prev = make(map[string]string)
+	}
+	var removed, added, changed bool
+	for k, v := range prev {
+		cur, ok := entries[k]
+		if !ok {
+			removed = true
+		} else if cur != v {
+			changed = true
+		}
+	}
+	for k := range entries {
+		if _, ok := prev[k]; !ok {
+			added = true
+		}
+	}
+	switch {
+	case removed:
+		ver = TokenListSemver{Major: ver.Major + 1}
+	case added:
+		ver = TokenListSemver{Major: ver.Major, Minor: ver.Minor + 1}
+	case changed:
+		ver.Patch++
+	}
+	return ver
+}
+
+// tokenListKeywords the unique keywords in the schema limits
+func tokenListKeywords(keywords []string) []string {
+	var rets []string
+	seen := make(map[string]bool)
+	for _, v := range keywords {
+		v = tokenListClean(v, tokenListWordReg, tokenListKeywordMax)
+		if v == "" || seen[v] || len(rets) >= tokenListKeywordsMax {
+			continue
+		}
+		seen[v] = true
+		rets = append(rets, v)
+	}
+	return rets
+}
+
+func newTokenList(last TokenListVersion, tags map[string]TokenListTag, tokens []TokenListToken) *TokenList {
+	cfg := conf.GetEnvConf().TokenList
+	rets := &TokenList{
+		Name:      tokenListClean(cfg.Name, tokenListWordReg, tokenListNameMax),
+		Timestamp: time.Unix(last.CreatedAt, 0).UTC().Format(time.RFC3339),
+		Version:   TokenListSemver{Major: last.Major, Minor: last.Minor, Patch: last.Patch},
+		Keywords:  tokenListKeywords(cfg.Keywords),
+		LogoURI:   cfg.LogoURI,
+		Tags:      tags,
+		Tokens:    tokens,
+	}
+	if rets.Name == "" {
+		rets.Name = tokenListDefaultName
+	}
+	return rets
+}
+
+// GetTokenList the token list of all ecosystems, the version is bumped when the entries change
+func GetTokenList() (*TokenList, error) {
+	tokenListLock.Lock()
+	defer tokenListLock.Unlock()
+	now := time.Now().Unix()
+	if tokenListCache != nil && now-tokenListTime < tokenListCacheTime {
+		return tokenListCache, nil
+	}
+
+	chainId, err := tokenListChainId()
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Get Token List Chain Id Failed")
+		return nil, err
+	}
+	tokens, tags, err := buildTokenListTokens(chainId)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Build Token List Failed")
+		return nil, err
+	}
+	entries, hash := tokenListEntries(tokens)
+
+	var last TokenListVersion
+	f, err := last.GetLast()
+	if err != nil {
+		return nil, err
+	}
+	if !f || last.Hash != hash {
+		ver := TokenListSemver{Major: 1}
+		if f {
+			ver = nextTokenListVersion(&last, entries)
+		}
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return nil, err
+		}
+		last = TokenListVersion{
+			Major:     ver.Major,
+			Minor:     ver.Minor,
+			Patch:     ver.Patch,
+			Hash:      hash,
+			Entries:   string(data),
+			CreatedAt: now,
+		}
+		if err = GetDB(nil).Create(&last).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	rets := newTokenList(last, tags, tokens)
+	tokenListCache = rets
+	tokenListTime = now
+	return rets, nil
+}
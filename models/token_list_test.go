@@ -0,0 +1,285 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IBAX-io/go-explorer/conf"
+)
+
+// tokenListSchemaRule a string constraint of https://uniswap.org/tokenlist.schema.json
+type tokenListSchemaRule struct {
+	pattern *regexp.Regexp
+	min     int
+	max     int
+}
+
+var tokenListSchema = map[string]tokenListSchemaRule{
+	"name":          {pattern: regexp.MustCompile(`^[\w ]+$`), min: 1, max: 30},
+	"keyword":       {pattern: regexp.MustCompile(`^[\w ]+$`), min: 1, max: 20},
+	"tagIdentifier": {pattern: regexp.MustCompile(`^[\w]+$`), min: 1, max: 10},
+	"tagName":       {pattern: regexp.MustCompile(`^[ \w]+$`), min: 1, max: 20},
+	"tagDesc":       {pattern: regexp.MustCompile(`^[ \w\.,:]+$`), min: 1, max: 200},
+	"address":       {pattern: regexp.MustCompile(`^0x[a-fA-F0-9]{40}$`), min: 42, max: 42},
+	"tokenName":     {pattern: regexp.MustCompile(`^[ \w.'+\-%/À-ÖØ-öø-ÿ:&\[\]\(\)]+$`), min: 1, max: 40},
+	"symbol":        {pattern: regexp.MustCompile(`^\S+$`), min: 1, max: 20},
+	"extensionKey":  {pattern: regexp.MustCompile(`^[\w]+$`), min: 1, max: 40},
+}
+
+func checkTokenListString(rule, path, v string) error {
+	r := tokenListSchema[rule]
+	if n := utf8.RuneCountInString(v); n < r.min || n > r.max {
+		return fmt.Errorf("%s %q length %d out of [%d,%d]", path, v, n, r.min, r.max)
+	}
+	if !r.pattern.MatchString(v) {
+		return fmt.Errorf("%s %q doesn't match %s", path, v, r.pattern)
+	}
+	return nil
+}
+
+// checkTokenListExtension the extension values are primitives or objects nested at most 2 levels
+func checkTokenListExtension(path string, v any, depth int) error {
+	switch val := v.(type) {
+	case nil, bool, float64:
+		return nil
+	case string:
+		if utf8.RuneCountInString(val) > 42 {
+			return fmt.Errorf("%s string too long", path)
+		}
+		return nil
+	case map[string]any:
+		if depth >= 2 || len(val) > 10 {
+			return fmt.Errorf("%s object too deep or large", path)
+		}
+		for k, sub := range val {
+			if err := checkTokenListString("extensionKey", path+"."+k, k); err != nil {
+				return err
+			}
+			if err := checkTokenListExtension(path+"."+k, sub, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s type %T not allowed", path, v)
+	}
+}
+
+// validateTokenList checks the json of the token list against the constraints of the published schema
+func validateTokenList(data []byte) []error {
+	var list struct {
+		Name      string         `json:"name"`
+		Timestamp string         `json:"timestamp"`
+		Version   map[string]int `json:"version"`
+		Keywords  []string       `json:"keywords"`
+		Tags      map[string]struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		} `json:"tags"`
+		Tokens []struct {
+			ChainId    int64          `json:"chainId"`
+			Address    string         `json:"address"`
+			Name       string         `json:"name"`
+			Symbol     string         `json:"symbol"`
+			Decimals   int            `json:"decimals"`
+			Tags       []string       `json:"tags"`
+			Extensions map[string]any `json:"extensions"`
+		} `json:"tokens"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return []error{err}
+	}
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	add(checkTokenListString("name", "name", list.Name))
+	if _, err := time.Parse(time.RFC3339, list.Timestamp); err != nil {
+		add(err)
+	}
+	for _, k := range []string{"major", "minor", "patch"} {
+		if v, ok := list.Version[k]; !ok || v < 0 {
+			add(fmt.Errorf("version.%s invalid", k))
+		}
+	}
+	if len(list.Keywords) > 20 {
+		add(fmt.Errorf("keywords too many"))
+	}
+	seen := make(map[string]bool)
+	for i, v := range list.Keywords {
+		add(checkTokenListString("keyword", fmt.Sprintf("keywords[%d]", i), v))
+		if seen[v] {
+			add(fmt.Errorf("keyword %s duplicated", v))
+		}
+		seen[v] = true
+	}
+	if len(list.Tags) > 20 {
+		add(fmt.Errorf("tags too many"))
+	}
+	for id, tag := range list.Tags {
+		add(checkTokenListString("tagIdentifier", "tags", id))
+		add(checkTokenListString("tagName", "tags."+id+".name", tag.Name))
+		add(checkTokenListString("tagDesc", "tags."+id+".description", tag.Description))
+	}
+	if len(list.Tokens) < 1 {
+		add(fmt.Errorf("tokens empty"))
+	}
+	for i, v := range list.Tokens {
+		path := fmt.Sprintf("tokens[%d]", i)
+		if v.ChainId < 1 {
+			add(fmt.Errorf("%s.chainId %d invalid", path, v.ChainId))
+		}
+		add(checkTokenListString("address", path+".address", v.Address))
+		add(checkTokenListString("tokenName", path+".name", v.Name))
+		add(checkTokenListString("symbol", path+".symbol", v.Symbol))
+		if v.Decimals < 0 || v.Decimals > 255 {
+			add(fmt.Errorf("%s.decimals %d invalid", path, v.Decimals))
+		}
+		if len(v.Tags) > 10 {
+			add(fmt.Errorf("%s.tags too many", path))
+		}
+		for _, tag := range v.Tags {
+			add(checkTokenListString("tagIdentifier", path+".tags", tag))
+			if _, ok := list.Tags[tag]; !ok {
+				add(fmt.Errorf("%s.tags %s not defined", path, tag))
+			}
+		}
+		if len(v.Extensions) > 10 {
+			add(fmt.Errorf("%s.extensions too many", path))
+		}
+		for k, ext := range v.Extensions {
+			add(checkTokenListString("extensionKey", path+".extensions", k))
+			add(checkTokenListExtension(path+".extensions."+k, ext, 0))
+		}
+	}
+	return errs
+}
+
+func TestTokenListSchema(t *testing.T) {
+	cfg := conf.GetEnvConf()
+	saved := cfg.TokenList
+	defer func() { cfg.TokenList = saved }()
+	cfg.TokenList.Name = "IBAX Token List (main-net)!"
+	cfg.TokenList.Keywords = []string{"ibax", "ibax", "defi/dex", "a very long keyword of the ibax list", "@@"}
+
+	sources := []tokenListSource{
+		{Ecosystem: Ecosystem{ID: 1, Name: "platform ecosystem", TokenSymbol: "IBXC"}, Decimals: 12},
+		{Ecosystem: Ecosystem{ID: 2, TokenName: "An extremely long token name that goes over the forty limit",
+			TokenSymbol: "LONG SYMBOL"}, Decimals: 6, EcoTag: "Decentralized Finance!"},
+		{Ecosystem: Ecosystem{ID: 31, TokenName: "Défi 🚀 token <b>", TokenSymbol: "DEFI"}, Decimals: 18,
+			Bridge: &tokenListBridge{ChainId: 56, TokenAddress: "0x55d398326f99059fF775485246999027B3197955"}},
+		{Ecosystem: Ecosystem{ID: 9223372036854775807, TokenName: "🚀", TokenSymbol: "MAX"}, EcoTag: "🚀"},
+	}
+	for i := 0; i < 25; i++ {
+		sources = append(sources, tokenListSource{Ecosystem: Ecosystem{ID: int64(100 + i), TokenSymbol: "T"},
+			EcoTag: fmt.Sprintf("Decentralized Finance %d", i)})
+	}
+	tags := newTokenListTags()
+	var tokens []TokenListToken
+	for _, v := range sources {
+		tokens = append(tokens, newTokenListToken(1001, v, tags))
+	}
+	list := newTokenList(TokenListVersion{Major: 1, Minor: 2, CreatedAt: 1700000000}, tags.tags, tokens)
+	data, err := json.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range validateTokenList(data) {
+		t.Error(err)
+	}
+
+	if got := tokens[2].Address; got != "0x000000000000000000000000000000000000001f" {
+		t.Errorf("address = %s", got)
+	}
+	if got := tokens[2].Extensions["ecosystem"]; got != int64(31) {
+		t.Errorf("extensions.ecosystem = %v", got)
+	}
+	if got := tokens[3].Name; got != "MAX" {
+		t.Errorf("empty name should fall back to the symbol, got %q", got)
+	}
+	if got := strings.Join(list.Keywords, ","); got != "ibax,defi dex,a very long keyword" {
+		t.Errorf("keywords = %s", got)
+	}
+}
+
+func TestTokenListTag(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "DeFi", want: "defi"},
+		{name: "Decentralized Finance!", want: "decentrali"},
+		{name: "Game & NFT", want: "game_nft"},
+		{name: "nft_______x", want: "nft"},
+		{name: "🚀", want: ""},
+	}
+	for _, tt := range tests {
+		if got := tokenListTag(tt.name); got != tt.want {
+			t.Errorf("tokenListTag(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTokenListTags(t *testing.T) {
+	tags := newTokenListTags()
+	first := TokenListTag{Name: "Decentralized Financ", Description: "a"}
+	if id := tags.add(tokenListTag(first.Name), first); id != "decentrali" {
+		t.Fatalf("id = %s", id)
+	}
+	if id := tags.add(tokenListTag(first.Name), first); id != "decentrali" {
+		t.Fatalf("the same tag should keep the id, got %s", id)
+	}
+	for i, want := range []string{"decentral2", "decentral3"} {
+		tag := TokenListTag{Name: fmt.Sprintf("Decentralized X %d", i), Description: "b"}
+		if id := tags.add(tokenListTag(tag.Name), tag); id != want {
+			t.Fatalf("id = %s, want %s", id, want)
+		}
+	}
+	for i := len(tags.tags); i < tokenListTagsMax; i++ {
+		tags.add(fmt.Sprintf("tag%d", i), TokenListTag{Name: fmt.Sprintf("Tag %d", i)})
+	}
+	if id := tags.add("overflow", TokenListTag{Name: "Overflow"}); id != "" || len(tags.tags) != tokenListTagsMax {
+		t.Fatalf("tags over the max: id %q, %d tags", id, len(tags.tags))
+	}
+	if id := tags.add(tokenListTag(first.Name), first); id != "decentrali" {
+		t.Fatalf("a known tag should be kept at the max, got %q", id)
+	}
+}
+
+func TestNextTokenListVersion(t *testing.T) {
+	prev := map[string]string{"0x1": "a", "0x2": "b"}
+	data, _ := json.Marshal(prev)
+	last := &TokenListVersion{Major: 2, Minor: 3, Patch: 4, Entries: string(data)}
+	tests := []struct {
+		name    string
+		entries map[string]string
+		want    TokenListSemver
+	}{
+		{name: "unchanged", entries: map[string]string{"0x1": "a", "0x2": "b"}, want: TokenListSemver{2, 3, 4}},
+		{name: "changed bumps patch", entries: map[string]string{"0x1": "a", "0x2": "c"}, want: TokenListSemver{2, 3, 5}},
+		{name: "added bumps minor", entries: map[string]string{"0x1": "a", "0x2": "c", "0x3": "d"}, want: TokenListSemver{2, 4, 0}},
+		{name: "removed bumps major", entries: map[string]string{"0x1": "a", "0x3": "d"}, want: TokenListSemver{3, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextTokenListVersion(last, tt.entries); got != tt.want {
+				t.Fatalf("version = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if got := nextTokenListVersion(&TokenListVersion{Major: 1, Entries: "bad"}, prev); got != (TokenListSemver{1, 1, 0}) {
+		t.Fatalf("version from invalid entries = %+v", got)
+	}
+}
@@ -0,0 +1,275 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-explorer/conf"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	tokenPriceBatch       = 1000
+	tokenPriceRollbackMax = "token_price_rollback_max"
+)
+
+var (
+	tokenPriceLock sync.Mutex
+
+	// CandleResolutions the supported candle resolutions in seconds
+	CandleResolutions = map[string]int64{"5m": 300, "1h": 3600, "1d": 86400}
+)
+
+// TokenPricePoint the pair reserves and the token price after a block that touched the pair.
+// The reserves come from the rollback_tx of the pair table: the old reserves of the first change in a block are the
+// reserves after the previous block, the reserves of the latest block are the current pair reserves
+type TokenPricePoint struct {
+	ID         int64           `gorm:"primary_key;not null" json:"-"`
+	PairId     int64           `gorm:"not null;uniqueIndex:idx_token_price_pair_block" json:"pair_id"`
+	Ecosystem  int64           `gorm:"not null;index:idx_token_price_eco_time" json:"ecosystem"` //the priced ecosystem
+	Block      int64           `gorm:"not null;uniqueIndex:idx_token_price_pair_block" json:"block"`
+	Time       int64           `gorm:"not null;index:idx_token_price_eco_time" json:"time"`
+	RollbackId int64           `gorm:"not null;index" json:"-"`
+	Reserve1   decimal.Decimal `gorm:"type:decimal(30);not null" json:"reserve1"`
+	Reserve2   decimal.Decimal `gorm:"type:decimal(30);not null" json:"reserve2"`
+	Price      decimal.Decimal `gorm:"type:numeric;not null" json:"price"`
+}
+
+type PriceCandle struct {
+	Time  int64           `json:"time"`
+	Open  decimal.Decimal `json:"open"`
+	High  decimal.Decimal `json:"high"`
+	Low   decimal.Decimal `json:"low"`
+	Close decimal.Decimal `json:"close"`
+}
+
+type PriceCandleResponse struct {
+	Ecosystem     int64         `json:"ecosystem"`
+	BaseEcosystem int64         `json:"base_ecosystem"`
+	Resolution    string        `json:"resolution"`
+	List          []PriceCandle `json:"list"`
+}
+
+type TokenPriceAtResponse struct {
+	Ecosystem int64  `json:"ecosystem"`
+	Time      int64  `json:"time"`
+	Block     int64  `json:"block"`
+	Price     string `json:"price"`
+}
+
+func (p *TokenPricePoint) TableName() string {
+	return "token_price_point"
+}
+
+func (p *TokenPricePoint) CreateTable() (err error) {
+	err = nil
+	if !HasTableOrView(p.TableName()) {
+		if err = GetDB(nil).Migrator().CreateTable(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func InitTokenPriceHistory() error {
+	if !conf.GetEnvConf().Defi.Enable {
+		return nil
+	}
+	var p TokenPricePoint
+	return p.CreateTable()
+}
+
+type pairRollback struct {
+	Id      int64
+	BlockId int64
+	PairId  int64
+	Data    string
+	Time    int64
+}
+
+// SyncTokenPriceHistory records the price points of the blocks that touched the base ecosystem pairs
+func SyncTokenPriceHistory() {
+	HistoryWG.Add(1)
+	defer HistoryWG.Done()
+	if !conf.GetEnvConf().Defi.Enable || AllowRankEcosystem == 0 {
+		return
+	}
+	if !tokenPriceLock.TryLock() {
+		return
+	}
+	defer tokenPriceLock.Unlock()
+
+	var (
+		p    TokenPricePoint
+		pair Pair
+		last int64
+		list []pairRollback
+	)
+	if !HasTableOrView(p.TableName()) {
+		return
+	}
+	err := GetDB(nil).Model(&p).Select("COALESCE(max(rollback_id),0)").Take(&last).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("get token price last rollback id failed")
+		return
+	}
+	var checkpoint BlockID
+	if f, err := checkpoint.GetByName(tokenPriceRollbackMax); err == nil && f && checkpoint.ID > last {
+		last = checkpoint.ID
+	}
+	err = GetDB(nil).Raw(`
+SELECT rt.id,rt.block_id,CAST(COALESCE(NULLIF(SPLIT_PART(rt.table_id,',',1),''),'0') AS BIGINT) AS pair_id,rt.data,
+	COALESCE(bk.time,0) AS time
+FROM rollback_tx AS rt LEFT JOIN block_chain AS bk ON(bk.id = rt.block_id)
+WHERE rt.table_name = ? AND rt.id > ? ORDER BY rt.id ASC LIMIT ?
+`, pair.TableName(), last, tokenPriceBatch).Find(&list).Error
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("get pair rollback list failed")
+		return
+	}
+
+	pairs := make(map[int64]*PairEcosystem)
+	for _, v := range list {
+		cur, ok := pairs[v.PairId]
+		if !ok {
+			var pe PairEcosystem
+			f, err := isFound(GetDB(nil).Table(pair.TableName()).Select("ecosystem1,ecosystem2,id,reserve1,reserve2,liquidity").
+				Where("id = ?", v.PairId).Take(&pe))
+			if err != nil {
+				log.WithFields(log.Fields{"error": err, "pair": v.PairId}).Error("get pair failed")
+				return
+			}
+			if f && (pe.Ecosystem1 == AllowRankEcosystem || pe.Ecosystem2 == AllowRankEcosystem) {
+				cur = &pe
+			}
+			pairs[v.PairId] = cur
+		}
+		if err = insertTokenPricePoint(v, cur); err != nil {
+			log.WithFields(log.Fields{"error": err, "rollback": v.Id}).Error("insert token price point failed")
+			return
+		}
+		checkpoint.ID = v.Id
+	}
+	if len(list) > 0 {
+		checkpoint.Name = tokenPriceRollbackMax
+		checkpoint.Time = time.Now().Unix()
+		if err = checkpoint.InsertRedis(); err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("token price checkpoint insert redis failed")
+		}
+	}
+}
+
+func insertTokenPricePoint(v pairRollback, pair *PairEcosystem) error {
+	if pair == nil {
+		//not a pair of the base ecosystem
+		return nil
+	}
+	ecosystem := pair.Ecosystem1
+	if ecosystem == AllowRankEcosystem {
+		ecosystem = pair.Ecosystem2
+	}
+
+	var prev TokenPricePoint
+	f, err := isFound(GetDB(nil).Where("pair_id = ?", pair.Id).Order("block desc").Take(&prev))
+	if err != nil {
+		return err
+	}
+	if f && prev.Block == v.BlockId {
+		return GetDB(nil).Model(&prev).Update("rollback_id", v.Id).Error
+	}
+	if f && v.Data != "" {
+		var data struct {
+			Reserve1 decimal.Decimal `json:"reserve1"`
+			Reserve2 decimal.Decimal `json:"reserve2"`
+		}
+		if err = json.Unmarshal([]byte(v.Data), &data); err == nil && (!data.Reserve1.IsZero() || !data.Reserve2.IsZero()) {
+			if data.Reserve1.IsZero() {
+				data.Reserve1 = prev.Reserve1
+			}
+			if data.Reserve2.IsZero() {
+				data.Reserve2 = prev.Reserve2
+			}
+			err = GetDB(nil).Model(&prev).Updates(map[string]any{
+				"reserve1": data.Reserve1,
+				"reserve2": data.Reserve2,
+				"price":    getPairPrice(ecosystem, pair.Ecosystem1, data.Reserve1, data.Reserve2),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	item := TokenPricePoint{
+		PairId:     pair.Id,
+		Ecosystem:  ecosystem,
+		Block:      v.BlockId,
+		Time:       v.Time,
+		RollbackId: v.Id,
+		Reserve1:   pair.Reserve1,
+		Reserve2:   pair.Reserve2,
+		Price:      getPairPrice(ecosystem, pair.Ecosystem1, pair.Reserve1, pair.Reserve2),
+	}
+	return GetDB(nil).Create(&item).Error
+}
+
+// GetPriceCandles the OHLC candles of the ecosystem token price in AllowRankEcosystem tokens
+func GetPriceCandles(ecosystem int64, resolution string, startTime, endTime int64) (*PriceCandleResponse, error) {
+	interval, ok := CandleResolutions[resolution]
+	if !ok {
+		return nil, errors.New("resolution invalid")
+	}
+	if endTime <= 0 {
+		endTime = GetNowTimeUnix()
+	}
+	if startTime <= 0 {
+		startTime = endTime - interval*500
+	}
+	if startTime > endTime || (endTime-startTime)/interval > 2000 {
+		return nil, errors.New("time range invalid")
+	}
+	var rets PriceCandleResponse
+	rets.Ecosystem = ecosystem
+	rets.BaseEcosystem = AllowRankEcosystem
+	rets.Resolution = resolution
+	err := GetDB(nil).Raw(`
+SELECT bucket AS time,(array_agg(price ORDER BY block ASC))[1] AS open,max(price) AS high,min(price) AS low,
+	(array_agg(price ORDER BY block DESC))[1] AS close
+FROM(
+	SELECT time/?*? AS bucket,price,block FROM token_price_point WHERE ecosystem = ? AND time >= ? AND time <= ? AND price > 0
+)AS v1 GROUP BY bucket ORDER BY bucket ASC
+`, interval, interval, ecosystem, startTime, endTime).Find(&rets.List).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rets, nil
+}
+
+// GetTokenPriceAt the token price in effect at the time
+func GetTokenPriceAt(ecosystem, t int64) (*TokenPriceAtResponse, error) {
+	var rets TokenPriceAtResponse
+	rets.Ecosystem = ecosystem
+	rets.Time = t
+	rets.Price = "0"
+	if ecosystem == AllowRankEcosystem {
+		rets.Price = "1"
+		return &rets, nil
+	}
+	var p TokenPricePoint
+	f, err := isFound(GetDB(nil).Where("ecosystem = ? AND time <= ? AND price > 0", ecosystem, t).Order("time desc,block desc").Take(&p))
+	if err != nil {
+		return nil, err
+	}
+	if f {
+		rets.Block = p.Block
+		rets.Price = p.Price.String()
+	}
+	return &rets, nil
+}
@@ -0,0 +1,174 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/common/crypto"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+)
+
+const (
+	MerkleLeft  = "left"
+	MerkleRight = "right"
+)
+
+type MerkleProofStep struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"` //sibling position: left or right
+}
+
+type TxProofResponse struct {
+	TxHash       string             `json:"tx_hash"`
+	Leaf         string             `json:"leaf"`
+	Index        int                `json:"index"`
+	TxCount      int                `json:"tx_count"`
+	Proof        []MerkleProofStep  `json:"proof"`
+	Root         string             `json:"root"`
+	BlockRoot    string             `json:"block_root"` //hex encoding as the block detail merkle root
+	RootMatch    bool               `json:"root_match"`
+	Header       BlockHeaderInfoHex `json:"header"`
+	RollbackHash string             `json:"rollbacks_hash"`
+}
+
+// merkleLeaf the leaf of block merkle tree is the double hash of the hex encoding tx hash
+func merkleLeaf(txHash []byte) []byte {
+	return []byte(hex.EncodeToString(crypto.DoubleHash([]byte(hex.EncodeToString(txHash)))))
+}
+
+func merkleParent(left, right []byte) []byte {
+	return []byte(hex.EncodeToString(crypto.DoubleHash(append(append([]byte{}, left...), right...))))
+}
+
+// buildMerkleProof returns the tree root and the sibling path of the leaf at index.
+// The last node of an odd level is promoted to the next level without hashing
+func buildMerkleProof(leaves [][]byte, index int) ([]byte, []MerkleProofStep) {
+	var proof []MerkleProofStep
+	level := leaves
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 >= len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			if index == i {
+				proof = append(proof, MerkleProofStep{Hash: string(level[i+1]), Position: MerkleRight})
+			} else if index == i+1 {
+				proof = append(proof, MerkleProofStep{Hash: string(level[i]), Position: MerkleLeft})
+			}
+			next = append(next, merkleParent(level[i], level[i+1]))
+		}
+		index /= 2
+		level = next
+	}
+	if len(level) == 0 {
+		return nil, proof
+	}
+	return level[0], proof
+}
+
+// VerifyMerkleProof checks offline that the tx hash is included in the merkle root
+func VerifyMerkleProof(txHash string, proof []MerkleProofStep, root string) (bool, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return false, err
+	}
+	node := merkleLeaf(hash)
+	for _, step := range proof {
+		switch step.Position {
+		case MerkleLeft:
+			node = merkleParent([]byte(step.Hash), node)
+		case MerkleRight:
+			node = merkleParent(node, []byte(step.Hash))
+		default:
+			return false, errors.New("invalid proof position:" + step.Position)
+		}
+	}
+	return string(node) == root, nil
+}
+
+func GetTxProof(hash string) (*TxProofResponse, error) {
+	hashHex, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, err
+	}
+	var lt LogTransaction
+	f, err := lt.GetBlockIdByHash(hashHex)
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("doesn't not hash")
+	}
+	var bk Block
+	f, err = bk.GetId(lt.Block)
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("block doesn't not exist")
+	}
+	blck, err := block.UnmarshallBlock(bytes.NewBuffer(bk.Data), false)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	leaves := make([][]byte, 0, len(blck.Transactions))
+	for i, tx := range blck.Transactions {
+		if bytes.Equal(tx.Hash(), hashHex) {
+			index = i
+		}
+		leaves = append(leaves, merkleLeaf(tx.Hash()))
+	}
+	if index < 0 {
+		return nil, errors.New("transaction doesn't not in block data")
+	}
+	root, proof := buildMerkleProof(leaves, index)
+
+	rets := &TxProofResponse{
+		TxHash:       hash,
+		Leaf:         string(leaves[index]),
+		Index:        index,
+		TxCount:      len(leaves),
+		Proof:        proof,
+		Root:         string(root),
+		BlockRoot:    hex.EncodeToString(blck.MerkleRoot),
+		RollbackHash: hex.EncodeToString(bk.RollbacksHash),
+	}
+	rets.RootMatch = bytes.Equal(root, blck.MerkleRoot)
+	rets.Header = BlockHeaderInfoHex{
+		BlockId:       blck.Header.BlockId,
+		Time:          blck.Header.Timestamp,
+		EcosystemId:   blck.Header.EcosystemId,
+		KeyId:         converter.AddressToString(blck.Header.KeyId),
+		NodePosition:  blck.Header.NodePosition,
+		Sign:          hex.EncodeToString(blck.Header.Sign),
+		BlockHash:     hex.EncodeToString(blck.Header.BlockHash),
+		Version:       blck.Header.Version,
+		ConsensusMode: blck.Header.ConsensusMode,
+	}
+	if bk.ID > 1 {
+		var prev Block
+		f, err = prev.GetId(bk.ID - 1)
+		if err != nil {
+			return nil, err
+		}
+		if f {
+			rets.Header.PreHash = hex.EncodeToString(prev.Hash)
+		}
+	}
+	if rets.Header.EcosystemId == 0 {
+		rets.Header.EcosystemId = 1
+	}
+
+	return rets, nil
+}
@@ -0,0 +1,98 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/common/crypto"
+)
+
+// goIbaxMerkleTreeRoot is the merkle root as computed by the block generator of go-ibax:
+// the leaves are the hex encoding tx hashes, the last node of an odd level is carried up
+func goIbaxMerkleTreeRoot(dataArray [][]byte) []byte {
+	result := make(map[int32][][]byte)
+	for _, v := range dataArray {
+		result[0] = append(result[0], []byte(hex.EncodeToString(crypto.DoubleHash(v))))
+	}
+	var j int32
+	for len(result[j]) > 1 {
+		for i := 0; i < len(result[j]); i = i + 2 {
+			if len(result[j]) <= (i + 1) {
+				result[j+1] = append(result[j+1], result[j][i])
+			} else {
+				hash := crypto.DoubleHash(append(append([]byte{}, result[j][i]...), result[j][i+1]...))
+				result[j+1] = append(result[j+1], []byte(hex.EncodeToString(hash)))
+			}
+		}
+		j++
+	}
+	return result[int32(len(result)-1)][0]
+}
+
+func TestMerkleRootMatchesBlock(t *testing.T) {
+	var mrkl, leaves [][]byte
+	for i := 0; i < 11; i++ {
+		hash := crypto.DoubleHash([]byte{byte(i), 0x5e})
+		mrkl = append(mrkl, []byte(hex.EncodeToString(hash)))
+		leaves = append(leaves, merkleLeaf(hash))
+		want := goIbaxMerkleTreeRoot(mrkl)
+		root, _ := buildMerkleProof(leaves, 0)
+		if string(root) != string(want) {
+			t.Fatalf("%d txs: root = %s, block merkle root = %s", i+1, root, want)
+		}
+	}
+}
+
+func TestBuildMerkleProof(t *testing.T) {
+	hashes := make([][]byte, 7)
+	leaves := make([][]byte, 7)
+	for i := range hashes {
+		hashes[i] = []byte{byte(i + 1), 0xaa, byte(i * 3)}
+		leaves[i] = merkleLeaf(hashes[i])
+	}
+	tests := []struct {
+		name      string
+		count     int
+		index     int
+		proofSize int
+	}{
+		{name: "single tx", count: 1, index: 0, proofSize: 0},
+		{name: "two txs left", count: 2, index: 0, proofSize: 1},
+		{name: "two txs right", count: 2, index: 1, proofSize: 1},
+		{name: "odd level middle", count: 5, index: 2, proofSize: 3},
+		{name: "odd level promoted last", count: 5, index: 4, proofSize: 1},
+		{name: "seven txs", count: 7, index: 5, proofSize: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, proof := buildMerkleProof(leaves[:tt.count], tt.index)
+			if len(proof) != tt.proofSize {
+				t.Fatalf("proof size = %d, want %d", len(proof), tt.proofSize)
+			}
+			txHash := hex.EncodeToString(hashes[tt.index])
+			ok, err := VerifyMerkleProof(txHash, proof, string(root))
+			if err != nil || !ok {
+				t.Fatalf("verify = %v, %v", ok, err)
+			}
+			other := hex.EncodeToString(hashes[(tt.index+1)%len(hashes)])
+			if ok, _ = VerifyMerkleProof(other, proof, string(root)); ok {
+				t.Fatal("proof should not verify another tx")
+			}
+		})
+	}
+}
+
+func TestVerifyMerkleProofInvalid(t *testing.T) {
+	if _, err := VerifyMerkleProof("zz", nil, ""); err == nil {
+		t.Fatal("expected error for the invalid tx hash")
+	}
+	proof := []MerkleProofStep{{Hash: "00", Position: "up"}}
+	if _, err := VerifyMerkleProof("01", proof, ""); err == nil {
+		t.Fatal("expected error for the invalid position")
+	}
+}
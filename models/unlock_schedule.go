@@ -0,0 +1,193 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	UnlockSourceAssign  = "assign"
+	UnlockSourceAirdrop = "airdrop"
+
+	UnlockIntervalDay   = "day"
+	UnlockIntervalMonth = "month"
+
+	unlockMaxMonths = 60
+)
+
+type UnlockItem struct {
+	Account  string          `json:"account"`
+	Source   string          `json:"source"`
+	Type     int64           `json:"type"` //assign type, 0 for airdrop
+	Amount   decimal.Decimal `json:"amount"`
+	UnlockAt int64           `json:"unlock_at"`
+}
+
+type UnlockScheduleChartResponse struct {
+	TokenSymbol string   `json:"token_symbol"`
+	Digits      int      `json:"digits"`
+	Interval    string   `json:"interval"`
+	Time        []int64  `json:"time"`
+	Amount      []string `json:"amount"`
+	Total       string   `json:"total"`
+}
+
+// getUnlockItems the pending releases of the assign and airdrop locks unlocking in [start,end].
+// The stake locks in 1_keys.lock have no unlock time, they are not part of the schedule
+func getUnlockItems(account string, start, end int64) ([]UnlockItem, error) {
+	var items []UnlockItem
+	if AssignReady {
+		var list []AssignInfo
+		query := GetDB(nil).Select("account,type,detail").Where("deleted = 0 AND balance_amount > 0")
+		if account != "" {
+			query = query.Where("account = ?", account)
+		}
+		if err := query.Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, v := range list {
+			details, err := getAssignDetail(v.Detail, v.Type)
+			if err != nil {
+				log.WithFields(log.Fields{"error": err, "account": v.Account}).Warn("get assign unlock detail failed")
+				continue
+			}
+			items = appendUnlockItems(items, details, v.Account, UnlockSourceAssign, v.Type, start, end)
+		}
+	}
+	if AirdropReady {
+		var list []AirdropInfo
+		query := GetDB(nil).Select("account,detail").Where("balance_amount > 0")
+		if account != "" {
+			query = query.Where("account = ?", account)
+		}
+		if err := query.Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, v := range list {
+			details, err := getAssignDetail(v.Detail, 1)
+			if err != nil {
+				log.WithFields(log.Fields{"error": err, "account": v.Account}).Warn("get airdrop unlock detail failed")
+				continue
+			}
+			items = appendUnlockItems(items, details, v.Account, UnlockSourceAirdrop, 0, start, end)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].UnlockAt == items[j].UnlockAt {
+			return items[i].Account < items[j].Account
+		}
+		return items[i].UnlockAt < items[j].UnlockAt
+	})
+	return items, nil
+}
+
+func appendUnlockItems(items []UnlockItem, details []assignDetail, account, source string, tp, start, end int64) []UnlockItem {
+	for _, d := range details {
+		//status 1: not claimed
+		if d.Status != 1 {
+			continue
+		}
+		st, _ := strconv.ParseInt(d.StartAt, 10, 64)
+		if st < start || st > end {
+			continue
+		}
+		amount, _ := decimal.NewFromString(d.Amount)
+		if amount.LessThanOrEqual(decimal.Zero) {
+			continue
+		}
+		items = append(items, UnlockItem{Account: account, Source: source, Type: tp, Amount: amount, UnlockAt: st})
+	}
+	return items
+}
+
+func unlockPeriodStart(t time.Time, interval string) time.Time {
+	if interval == UnlockIntervalMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func checkUnlockParams(interval string, months int) error {
+	if interval != UnlockIntervalDay && interval != UnlockIntervalMonth {
+		return errors.New("interval invalid")
+	}
+	if months <= 0 || months > unlockMaxMonths {
+		return errors.New("months invalid")
+	}
+	return nil
+}
+
+// GetUnlockScheduleChart the amount unlocking per day or month for the next months, the whole network if the account is empty
+func GetUnlockScheduleChart(account, interval string, months int) (*UnlockScheduleChartResponse, error) {
+	if err := checkUnlockParams(interval, months); err != nil {
+		return nil, err
+	}
+	var rets UnlockScheduleChartResponse
+	rets.TokenSymbol, rets.Digits = holderTokenInfo(1)
+	rets.Interval = interval
+
+	now := time.Unix(GetNowTimeUnix(), 0)
+	first := unlockPeriodStart(now, interval)
+	end := now.AddDate(0, months, 0)
+	items, err := getUnlockItems(account, now.Unix(), end.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make(map[int64]decimal.Decimal)
+	total := decimal.Zero
+	for _, v := range items {
+		key := unlockPeriodStart(time.Unix(v.UnlockAt, 0), interval).Unix()
+		amounts[key] = amounts[key].Add(v.Amount)
+		total = total.Add(v.Amount)
+	}
+	for t := first; !t.After(end); {
+		rets.Time = append(rets.Time, t.Unix())
+		rets.Amount = append(rets.Amount, amounts[t.Unix()].String())
+		if interval == UnlockIntervalMonth {
+			t = t.AddDate(0, 1, 0)
+		} else {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+	rets.Total = total.String()
+	return &rets, nil
+}
+
+// GetUpcomingUnlocks the releases unlocking in the next days, ordered by unlock time
+func GetUpcomingUnlocks(account string, days int64, page, limit int) (*GeneralResponse, error) {
+	if days <= 0 || days > unlockMaxMonths*31 {
+		return nil, errors.New("days invalid")
+	}
+	var rets GeneralResponse
+	rets.Page = page
+	rets.Limit = limit
+
+	now := GetNowTimeUnix()
+	items, err := getUnlockItems(account, now, now+days*86400)
+	if err != nil {
+		return nil, err
+	}
+	rets.Total = int64(len(items))
+	first := (page - 1) * limit
+	if first >= len(items) {
+		rets.List = []UnlockItem{}
+		return &rets, nil
+	}
+	last := first + limit
+	if last > len(items) {
+		last = len(items)
+	}
+	rets.List = items[first:last]
+	return &rets, nil
+}
@@ -0,0 +1,103 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/common/crypto"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+)
+
+type VerifySignatureResponse struct {
+	Valid     bool   `json:"valid"`
+	KeyId     string `json:"key_id"`
+	Address   string `json:"address"`
+	PublicKey string `json:"public_key"`
+	Message   string `json:"message,omitempty"`
+}
+
+func decodeHexString(str string) ([]byte, error) {
+	str = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(str), "0x"), "0X")
+	return hex.DecodeString(str)
+}
+
+// normalizePublicKey drops the 04 prefix of the uncompressed 65 bytes public key
+func normalizePublicKey(pub []byte) []byte {
+	if len(pub) == 65 && pub[0] == 0x04 {
+		return pub[1:]
+	}
+	return pub
+}
+
+// getAccountPublicKey returns the public key registered by the account, platform ecosystem first
+func getAccountPublicKey(keyId int64) ([]byte, error) {
+	var k Key
+	f, err := isFound(GetDB(nil).Table(k.TableName()).Select("ecosystem,id,pub").
+		Where("id = ? AND length(pub) > 0", keyId).Order("ecosystem asc").Limit(1).Take(&k))
+	if err != nil {
+		return nil, err
+	}
+	if !f {
+		return nil, errors.New("account public key doesn't not exist")
+	}
+	return normalizePublicKey(k.PublicKey), nil
+}
+
+// VerifySignature checks the signature of message under the configured crypto settings.
+// The public key is used when supplied, otherwise it is loaded from the account
+func VerifySignature(account, pubKey, message, signature string) (*VerifySignatureResponse, error) {
+	sign, err := decodeHexString(signature)
+	if err != nil || len(sign) == 0 {
+		return nil, errors.New("signature must be hex encoded")
+	}
+	var (
+		pub   []byte
+		keyId int64
+	)
+	if pubKey != "" {
+		pub, err = decodeHexString(pubKey)
+		if err != nil || len(pub) == 0 {
+			return nil, errors.New("public key must be hex encoded")
+		}
+		pub = normalizePublicKey(pub)
+		keyId = smart.PubToID(hex.EncodeToString(pub))
+		if account != "" {
+			id, err := accountKeyId(account)
+			if err != nil {
+				return nil, err
+			}
+			if id != keyId {
+				return nil, errors.New("public key doesn't not match the account")
+			}
+		}
+	} else {
+		keyId, err = accountKeyId(account)
+		if err != nil {
+			return nil, err
+		}
+		pub, err = getAccountPublicKey(keyId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rets := &VerifySignatureResponse{
+		KeyId:     strconv.FormatInt(keyId, 10),
+		Address:   converter.AddressToString(keyId),
+		PublicKey: hex.EncodeToString(pub),
+	}
+	rets.Valid, err = crypto.Verify(pub, []byte(message), sign)
+	if err != nil {
+		rets.Valid = false
+		rets.Message = err.Error()
+	}
+	return rets, nil
+}
@@ -0,0 +1,77 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAccountKeyId(t *testing.T) {
+	tests := []struct {
+		account string
+		want    int64
+		wantErr bool
+	}{
+		{account: "-123456", want: -123456},
+		{account: "123456", want: 123456},
+		{account: BlackHoleAddr, want: 0},
+		{account: "1234-5678", wantErr: true},
+		{account: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := accountKeyId(tt.account)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("accountKeyId(%q) error = %v", tt.account, err)
+		}
+		if got != tt.want {
+			t.Errorf("accountKeyId(%q) = %d, want %d", tt.account, got, tt.want)
+		}
+	}
+}
+
+func TestVerifySignatureParams(t *testing.T) {
+	pub := "04" + strings.Repeat("ab", 64)
+	tests := []struct {
+		name      string
+		account   string
+		pubKey    string
+		signature string
+		wantErr   string
+	}{
+		{name: "signature not hex", account: "-123456", signature: "zz", wantErr: "signature must be hex encoded"},
+		{name: "public key not hex", pubKey: "zz", signature: "0x01", wantErr: "public key must be hex encoded"},
+		{name: "negative key id is compared with the public key", account: "-123456", pubKey: pub, signature: "01",
+			wantErr: "public key doesn't not match the account"},
+		{name: "account invalid", account: "1234-5678", signature: "01", wantErr: "address must be 20 digits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifySignature(tt.account, tt.pubKey, "message", tt.signature)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizePublicKey(t *testing.T) {
+	key := make([]byte, 64)
+	key[0] = 0x04
+	tests := []struct {
+		pub  []byte
+		want int
+	}{
+		{pub: append([]byte{0x04}, key...), want: 64},
+		{pub: append([]byte{0x02}, key...), want: 65},
+		{pub: key, want: 64},
+	}
+	for _, tt := range tests {
+		if got := normalizePublicKey(tt.pub); len(got) != tt.want {
+			t.Errorf("normalizePublicKey(%x) length = %d, want %d", tt.pub, len(got), tt.want)
+		}
+	}
+}
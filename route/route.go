@@ -13,12 +13,14 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"strings"
+	"time"
 
 	"github.com/IBAX-io/go-explorer/controllers"
 
 	"github.com/IBAX-io/go-explorer/conf"
 	"github.com/IBAX-io/go-explorer/docs"
 	"github.com/didip/tollbooth"
+	tblimiter "github.com/didip/tollbooth/limiter"
 	"github.com/didip/tollbooth_gin"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -105,19 +107,35 @@ func Run(host string) (err error) {
 	api.GET(`/honor_node_map`, controllers.GetHonorNodeMapHandler)
 
 	api.GET(`/block_tps_list`, controllers.GetBlockTpsLists)
+	api.GET(`/block_integrity`, controllers.GetBlockIntegritySummaryHandler)
+	api.POST(`/block_integrity_report`, controllers.GetBlockIntegrityReportHandler)
 
 	//Global Search
 	api.GET(`/search_hash/:hash`, controllers.SearchHash)
+	api.GET(`/search`, controllers.GlobalSearchHandler)
 	api.GET(`/transaction_detail/:hash`, controllers.GetTransactionDetails)
 	api.GET(`/contract_tx_detail_list/:hash`, controllers.GetContractTxDetailListHandler)
 	api.GET(`/transaction_utxo_detail/:hash`, controllers.GetUtxoTransactionDetails)
 	api.GET(`/utxo_inputs/:hash`, controllers.GetUtxoInputsHandler)
 	api.GET(`/transaction_head/:hash`, controllers.GetTransactionHead)
+	api.POST(`/decode_tx`, controllers.DecodeTxHandler)
+	api.POST(`/broadcast`, controllers.BroadcastTxHandler)
+	api.GET(`/broadcast/:hash`, controllers.GetBroadcastTxStatusHandler)
+	api.GET(`/tx_proof/:hash`, controllers.GetTxProofHandler)
+	api.POST(`/tx_proof/verify`, controllers.VerifyTxProofHandler)
 	api.GET(`/block_detail/:block_id`, controllers.GetBlockDetails)
 	api.POST(`/account_detail`, controllers.GetAccountDetailEcosystem)
 	api.GET(`/account_detail_basis/:account`, controllers.GetAccountDetailBasisEcosystem)
 	api.GET(`/account_detail_basis_chart/:account`, controllers.GetAccountDetailBasisTokenChange)
 	api.GET(`/account_tx_count/:ecosystem/:account`, controllers.GetAccountTxCountHandler)
+	api.GET(`/address/convert/:value`, controllers.ConvertAddressHandler)
+	api.GET(`/address/validate/:address`, controllers.ValidateAddressHandler)
+	api.POST(`/address/from_pubkey`, controllers.AddressFromPubKeyHandler)
+	api.GET(`/address/ecosystems/:account`, controllers.GetKeyEcosystemsHandler)
+	api.POST(`/verify_signature`, controllers.VerifySignatureHandler)
+	api.GET(`/iname/:value`, controllers.ResolveINameHandler)
+	api.POST(`/address_label_list`, controllers.GetAddressLabelListHandler)
+	api.GET(`/address_label/:account`, controllers.GetAddressLabelHandler)
 	//Nft Miner Global Search
 	api.GET(`/nft_miner_info/:search`, controllers.NftMinerInfoHandler)
 	api.POST(`/nft_miner_history_info`, controllers.NftMinerHistoryInfoHandler)
@@ -134,6 +152,26 @@ func Run(host string) (err error) {
 	api.POST("/ecosystem_search", controllers.EcosystemSearchHandler)
 	api.POST(`/account_list`, controllers.GetAccountList)
 	api.GET(`/account_list_chart/:ecosystem`, controllers.GetAccountListChartHandler)
+	api.POST(`/holder_rich_list`, controllers.GetHolderRichListHandler)
+	api.GET(`/holder_distribution/:ecosystem`, controllers.GetHolderDistributionHandler)
+	api.POST(`/holder_distribution_chart`, controllers.GetHolderDistributionChartHandler)
+	//one snapshot request every ten seconds of each client
+	snapshotLimiter := tollbooth.NewLimiter(0.1, &tblimiter.ExpirableOptions{DefaultExpirationTTL: time.Hour})
+	api.POST(`/snapshots`, tollbooth_gin.LimitHandler(snapshotLimiter), controllers.CreateHolderSnapshotHandler)
+	api.POST(`/snapshots/diff`, controllers.GetHolderSnapshotDiffHandler)
+	api.GET(`/snapshots/:id`, controllers.GetHolderSnapshotHandler)
+	//one export every ten seconds of each client
+	exportLimiter := tollbooth.NewLimiter(0.1, &tblimiter.ExpirableOptions{DefaultExpirationTTL: time.Hour})
+	api.GET(`/snapshots/:id/download`, tollbooth_gin.LimitHandler(exportLimiter), controllers.DownloadHolderSnapshotHandler)
+	api.GET(`/supply/:ecosystem`, controllers.GetSupplyHandler)
+	api.GET(`/supply/:ecosystem/:type`, controllers.GetSupplyValueHandler)
+	api.POST(`/unlock_schedule_chart`, controllers.GetUnlockScheduleChartHandler)
+	api.POST(`/upcoming_unlocks`, controllers.GetUpcomingUnlocksHandler)
+	api.POST(`/burn_ledger`, controllers.GetBurnLedgerHandler)
+	api.GET(`/burn_summary/:ecosystem`, controllers.GetBurnSummaryHandler)
+	api.POST(`/fee_recipients`, controllers.GetFeeRecipientsHandler)
+	api.POST(`/node_fee_income`, controllers.GetNodeFeeIncomeHandler)
+	api.GET(`/fee_estimate`, controllers.GetFeeEstimateHandler)
 	api.POST(`/account_detail_tx`, controllers.GetAccountTransactionHistory)
 	api.POST(`/account_detail_nft_miner`, controllers.GetAccountDetailNftMinerHandler)
 
@@ -172,11 +210,22 @@ func Run(host string) (err error) {
 	api.POST(`/get_eco_detail_member`, controllers.GetEcosystemDetailMemberHandler)
 	api.POST(`/platform_ecosystem_param`, controllers.GetPlatformEcosystemParam)
 	api.POST(`/ecosystem_param`, controllers.GetEcosystemParam)
+	api.POST(`/parameter_timeline`, controllers.GetParameterTimelineHandler)
+	api.GET(`/parameter_history/:ecosystem/:name`, controllers.GetParameterHistoryHandler)
 	api.POST(`/get_eco_database`, controllers.GetEcosystemDatabaseHandler)
 	api.POST(`/get_eco_app`, controllers.GetEcosystemAppHandler)
 	api.GET(`/get_eco_app_export/:id`, controllers.GetEcosystemAppExportHandler)
 	api.POST(`/get_eco_attachment`, controllers.GetEcosystemAttachmentHandler)
 	api.GET(`/get_eco_attachment_export/:hash`, controllers.GetEcosystemAttachmentExportHandler)
+	api.POST(`/ecosystem_profile`, controllers.SubmitEcosystemProfileHandler)
+	api.GET(`/ecosystem_profile/:ecosystem`, controllers.GetEcosystemProfileHandler)
+	api.GET(`/ecosystem_roles/:ecosystem`, controllers.GetEcosystemRolesHandler)
+	api.POST(`/ecosystem_role_members`, controllers.GetEcosystemRoleMembersHandler)
+	api.POST(`/ecosystem_role_history`, controllers.GetEcosystemRoleHistoryHandler)
+	api.GET(`/ecosystem_permissions/:ecosystem`, controllers.GetPermissionMatrixHandler)
+	//one comparison every second of each client
+	compareLimiter := tollbooth.NewLimiter(1, &tblimiter.ExpirableOptions{DefaultExpirationTTL: time.Hour})
+	api.POST(`/ecosystem_compare`, tollbooth_gin.LimitHandler(compareLimiter), controllers.GetEcosystemCompareHandler)
 
 	//EcoLibs Detail Chart
 	ecoChartRoute := api.Group("/eco_chart")
@@ -186,6 +235,9 @@ func Run(host string) (err error) {
 	ecoChartRoute.GET(`/get_tx_account/:ecosystem`, controllers.GetEcoTopTenTxAccountChartHandler)
 	ecoChartRoute.GET(`/get_gas_combustion_pie/:ecosystem`, controllers.GetGasCombustionPieChartHandler)
 	ecoChartRoute.GET(`/get_gas_combustion_line/:ecosystem`, controllers.GetGasCombustionLineChartHandler)
+	ecoChartRoute.GET(`/get_burn/:ecosystem`, controllers.GetBurnChartHandler)
+	ecoChartRoute.GET(`/get_fee_component/:ecosystem`, controllers.GetFeeComponentChartHandler)
+	ecoChartRoute.GET(`/get_fuel_rate/:ecosystem`, controllers.GetFuelRateChartHandler)
 	ecoChartRoute.GET(`/get_tx_amount/:ecosystem`, controllers.GetEcoTxAmountChartHandler)
 	ecoChartRoute.GET(`/get_gas_fee/:ecosystem`, controllers.GetEcoGasFeeChartHandler)
 	ecoChartRoute.GET(`/get_new_key/:ecosystem`, controllers.GetEcoNewKeyChartHandler)
@@ -233,6 +285,15 @@ func Run(host string) (err error) {
 	api.GET("/get_node_region", controllers.GetNodeRegionChartHandler)
 	api.GET("/get_node_statistical_change", controllers.GetNodeStatisticalChangeHandler)
 	api.GET("/token_price", controllers.GetTokenPriceHandler)
+	api.GET("/token_price_candles", controllers.GetTokenPriceCandlesHandler)
+	api.GET("/token_price_at", controllers.GetTokenPriceAtHandler)
+	api.GET("/tokenlist.json", controllers.GetTokenListHandler)
+	api.POST("/dex_pair_list", controllers.GetDexPairListHandler)
+	api.GET("/dex_pair/:id", controllers.GetDexPairDetailHandler)
+	api.GET("/dex_tvl", controllers.GetDexTvlHandler)
+	api.POST("/bridge_transfers", controllers.GetBridgeTransfersHandler)
+	api.POST("/bridge_pending_withdrawals", controllers.GetBridgePendingWithdrawalsHandler)
+	api.GET("/bridge_volume_chart/:id", controllers.GetBridgeVolumeChartHandler)
 	api.GET("/ecosystem_logo", controllers.GetEcosystemLogoHandler)
 
 	//common
@@ -242,6 +303,14 @@ func Run(host string) (err error) {
 
 	api.GET(`/get_redis/:name`, controllers.GetRedisKey) //get redis keys
 
+	//admin
+	admin := api.Group("/admin", controllers.AdminAuth())
+	admin.POST("/address_label", controllers.SaveAddressLabelHandler)
+	admin.DELETE("/address_label/:account", controllers.DeleteAddressLabelHandler)
+	admin.POST("/address_label/import", controllers.ImportAddressLabelHandler)
+	admin.POST("/ecosystem_profiles", controllers.GetEcosystemProfileListHandler)
+	admin.POST("/ecosystem_profile/:id/review", controllers.ReviewEcosystemProfileHandler)
+
 	api.StaticFS("/flag", http.Dir("./flag"))
 
 	server = &http.Server{
@@ -7,10 +7,11 @@ package services
 
 import (
 	"encoding/json"
-	"io/ioutil"
-	"net/http"
+	"errors"
+	"time"
 
 	"github.com/IBAX-io/go-explorer/models"
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 )
 
@@ -134,219 +135,53 @@ func Get_Dashboard_Get_chain() (*map[string]any, error) {
 
 func GetCheckchain() {
 	if GDashboardChain != nil {
-		if _, ok := GDashboardChain["btc-ibax"]; ok {
-		} else {
-			if ret1, err1 := Getchaindat("https://api.coinegg.im/api/v1/ticker/region/btc?coin=ibxc"); err1 == nil {
-				GDashboardChain["btc-ibax"] = ret1
-			} else {
-				logrus.Info("btc-ibax Not Found")
-			}
-		}
-
-		if _, ok := GDashboardChain["usdt-ibax"]; ok {
-		} else {
-			if ret2, err2 := Getchaindat("https://api.coinegg.im/api/v1/ticker/region/usdt?coin=ibxc"); err2 == nil {
-				GDashboardChain["usdt-ibax"] = ret2
-			} else {
-				logrus.Info("usdt-ibax Not Found")
-			}
+		if _, err := Getchain(); err != nil {
+			logrus.Info("Dashboard Price Not Found:" + err.Error())
 		}
-
-		if _, ok := GDashboardChain["Rates"]; ok {
-		} else {
-			if ret3, err3 := GetBlockCCRatesdat("https://data.block.cc/api/v1/exchange_rate?base=usdt"); err3 == nil {
-				GDashboardChain["Rates"] = ret3
-			} else {
-				logrus.Info("Rates Not Found")
-			}
-		}
-
-		if _, ok := GDashboardChain["Price"]; ok {
-		} else {
-			if ret4, err4 := GetBlockCCPricedat("https://data.block.cc/api/v1/price?symbol_name=bitcoin"); err4 == nil {
-				GDashboardChain["Price"] = ret4
-			} else {
-				logrus.Info("Price Not Found")
-			}
-
-		}
-
 	}
-
-	//return  err
 }
 
+// Getchain the price of the platform token from the price sources and the fiat rates. The keys and the types of
+// the former ticker response are kept: btc-ibax and usdt-ibax carry the price in last, Rates the fiat rates per usdt,
+// Price the bitcoin price in usd when the BTC rate is configured. Oracle is the price with its source and staleness
 func Getchain() (*map[string]any, error) {
-	var (
-		err error
-	)
 	rets := make(map[string]any)
-	if ret1, err1 := Getchaindat("https://api.coinegg.im/api/v1/ticker/region/btc?coin=ibxc"); err1 == nil {
-		//rets = append(rets, *ret1)
-		rets["btc-ibax"] = ret1
-		GDashboardChain["btc-ibax"] = ret1
-	} else {
-		if GDashboardChain != nil {
-			if v, ok := GDashboardChain["btc-ibax"]; ok {
-				//fmt.Println(v)
-				rets["btc-ibax"] = v
-			} else {
-				logrus.Info("btc-ibax Not Found")
-				//fmt.Println("Key Not Found")
-			}
-
-		}
-		err = err1
-	}
-
-	if ret2, err2 := Getchaindat("https://api.coinegg.im/api/v1/ticker/region/usdt?coin=ibxc"); err2 == nil {
-		//rets = append(rets, *ret2)
-		rets["usdt-ibax"] = ret2
-		GDashboardChain["usdt-ibax"] = ret2
-	} else {
-		if GDashboardChain != nil {
-			if v, ok := GDashboardChain["usdt-ibax"]; ok {
-				//fmt.Println(v)
-				rets["usdt-ibax"] = v
-			} else {
-				logrus.Info("usdt-ibax Not Found")
-				//fmt.Println("Key Not Found")
-			}
-
-		}
-		err = err2
+	now := time.Now().Unix()
+	prices, err := models.GetOraclePrices([]int64{1}, models.DefaultPriceCurrency)
+	if err == nil && prices[0].Stale {
+		err = errors.New("no fresh price of the platform token")
 	}
-
-	if ret3, err3 := GetBlockCCRatesdat("https://data.block.cc/api/v1/exchange_rate?base=usdt"); err3 == nil {
-		//rets = append(rets, *ret1)
-		rets["Rates"] = ret3
-		GDashboardChain["Rates"] = ret3
-	} else {
-		if GDashboardChain != nil {
-			if v, ok := GDashboardChain["Rates"]; ok {
-				//fmt.Println(v)
-				rets["Rates"] = v
-			} else {
-				logrus.Info("Rates Not Found")
-				//fmt.Println("Key Not Found")
+	if err != nil {
+		//the last good prices are kept
+		for _, k := range []string{"btc-ibax", "usdt-ibax", "Price", "Oracle"} {
+			if v, ok := GDashboardChain[k]; ok {
+				rets[k] = v
 			}
-
 		}
-		err = err3
-	}
-
-	if ret4, err4 := GetBlockCCPricedat("https://data.block.cc/api/v1/price?symbol_name=bitcoin"); err4 == nil {
-		//rets = append(rets, *ret2)
-		rets["Price"] = ret4
-		GDashboardChain["Price"] = ret4
 	} else {
-		if GDashboardChain != nil {
-			if v, ok := GDashboardChain["Price"]; ok {
-				//fmt.Println(v)
-				rets["Price"] = v
-			} else {
-				logrus.Info("Price Not Found")
-				//fmt.Println("Key Not Found")
-			}
-
+		rets["Oracle"] = prices[0]
+		rets["usdt-ibax"] = &models.DashboardChainInfo{Last: prices[0].Price}
+		if btc, err := models.GetOraclePrices([]int64{1}, "BTC"); err == nil && !btc[0].Stale {
+			rets["btc-ibax"] = &models.DashboardChainInfo{Last: btc[0].Price}
+		} else if v, ok := GDashboardChain["btc-ibax"]; ok {
+			rets["btc-ibax"] = v
 		}
-		err = err4
-	}
-
-	return &rets, err
-}
-func Getchaindat(path string) (*models.DashboardChainInfo, error) {
-	ret := &models.DashboardChainInfo{}
-	client := &http.Client{}
-	request, err := http.NewRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-	request.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8")
-	request.Header.Set("Accept-Charset", "en;q=0.9")
-	request.Header.Set("Accept-Encoding", "")
-	request.Header.Set("Accept-Language", "en;q=0.9")
-	request.Header.Set("Cache-Control", "max-age=0")
-	request.Header.Set("Connection", "keep-alive")
-
-	if response, err := client.Do(request); err == nil {
-		defer response.Body.Close()
-		if response.StatusCode == 200 {
-			if body, err := ioutil.ReadAll(response.Body); err == nil {
-				if err := json.Unmarshal(body, ret); err == nil {
-					return ret, err
-				}
-				return ret, err
-			}
-			return ret, err
+		price := make([]map[string]any, 0, 1)
+		if rate, err := decimal.NewFromString(models.GetFiatRates()["BTC"]); err == nil && rate.IsPositive() {
+			price = append(price, map[string]any{"name": "bitcoin", "symbol": "BTC",
+				"price": decimal.NewFromInt(1).DivRound(rate, 8).String(), "timestamp": now})
 		}
-		return ret, err
-	}
-
-	return ret, err
-}
-func GetBlockCCRatesdat(path string) (*models.RatesInfo, error) {
-	retdat := &models.BlockCCRatesInfo{}
-	ret := &models.RatesInfo{}
-	client := &http.Client{}
-	//defer  client.
-	request, err := http.NewRequest("GET", path, nil)
-	request.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8")
-	request.Header.Set("Accept-Charset", "en;q=0.9")
-	request.Header.Set("Accept-Encoding", "")
-	request.Header.Set("Accept-Language", "en;q=0.9")
-	request.Header.Set("Cache-Control", "max-age=0")
-	request.Header.Set("Connection", "keep-alive")
-
-	if response, err := client.Do(request); err == nil {
-		defer response.Body.Close()
-		if response.StatusCode == 200 {
-			if body, err := ioutil.ReadAll(response.Body); err == nil {
-				if err := json.Unmarshal(body, retdat); err != nil {
-					return &retdat.Data, err
-				} else {
-					return &retdat.Data, err
-				}
-
-				return ret, err
-			}
-			return ret, err
+		rets["Price"] = &price
+		for k, v := range rets {
+			GDashboardChain[k] = v
 		}
-		return ret, err
-	}
-
-	return ret, err
-}
-func GetBlockCCPricedat(path string) (*[]map[string]any, error) {
-	retdat := &models.BlockCCPriceInfo{}
-	ret := make([]map[string]any, 1, 1)
-	//ret := &[]map[string]any
-	client := &http.Client{}
-	request, err := http.NewRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
 	}
-	request.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8")
-	request.Header.Set("Accept-Charset", "en;q=0.9")
-	request.Header.Set("Accept-Encoding", "")
-	request.Header.Set("Accept-Language", "en;q=0.9")
-	request.Header.Set("Cache-Control", "max-age=0")
-	request.Header.Set("Connection", "keep-alive")
-
-	if response, err := client.Do(request); err == nil {
-		defer response.Body.Close()
-		if response.StatusCode == 200 {
-			if body, err := ioutil.ReadAll(response.Body); err == nil {
-				if err := json.Unmarshal(body, retdat); err != nil {
-					return &retdat.Data, err
-				} else {
-					return &retdat.Data, err
-				}
-			}
-			return &ret, err
-		}
-		return &ret, err
+	rates := make(map[string]any)
+	for k, v := range models.GetFiatRates() {
+		rates[k] = v
 	}
+	rets["Rates"] = &models.RatesInfo{Base: "usdt", Timestamp: now, Rates: rates}
+	GDashboardChain["Rates"] = rets["Rates"]
 
-	return &ret, err
+	return &rets, err
 }